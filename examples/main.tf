@@ -33,6 +33,11 @@ data "tomato_nvram" "nvram" {}
 #  value = data.tomato_nvram.nvram.nvram.dhcpd_static
 #}
 
+#data "tomato_nvram" "vpn" {
+#  prefix     = "vpn_server1_"
+#  url_decode = true
+#}
+
 #resource "tomato_dns_entry" "potato" {
 #  name = "potato.com"
 #  record = "127.0.0.1"
@@ -47,3 +52,239 @@ data "tomato_nvram" "nvram" {}
 #  mac2 = "6C:60:6D:67:68:65"
 #  bind = false
 #}
+
+#resource "tomato_openvpn_server" "server1" {
+#  server      = 1
+#  protocol    = "udp"
+#  port        = 1194
+#  subnet      = "10.8.0.0/24"
+#  push_routes = ["10.6.4.0/24"]
+#
+#  ca   = tls_self_signed_cert.vpn_ca.cert_pem
+#  cert = tls_locally_signed_cert.vpn_server.cert_pem
+#  key  = tls_private_key.vpn_server.private_key_pem
+#  dh   = file("dh.pem")
+#
+#  ccd {
+#    common_name = "office"
+#    subnet      = "10.7.0.0/24"
+#    push        = true
+#  }
+#}
+
+#resource "tomato_openvpn_client" "vpn_provider" {
+#  client           = 1
+#  server_address   = "vpn.example.com"
+#  username         = "xxxxx"
+#  password         = "xxxxx"
+#  ca               = file("ca.pem")
+#  redirect_gateway = "policy"
+#
+#  routing_policy {
+#    type  = "source"
+#    value = "10.6.4.6"
+#  }
+#  routing_policy {
+#    type  = "domain"
+#    value = "example.org"
+#  }
+#}
+
+#resource "tomato_wireguard_interface" "wg0" {
+#  interface = 0
+#  address   = "10.9.0.1/24"
+#  port      = 51820
+#}
+
+#resource "tomato_wireguard_peer" "laptop" {
+#  interface   = tomato_wireguard_interface.wg0.interface
+#  name        = "laptop"
+#  public_key  = "xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg="
+#  allowed_ips = ["10.9.0.2/32"]
+#  keepalive   = 25
+#}
+
+#resource "tomato_admin_access" "admin" {
+#  http_enabled       = false
+#  https_enabled      = true
+#  https_port         = 443
+#  remote_allowed_ips = ["10.6.4.0/24"]
+#}
+
+#resource "tomato_ssh" "ssh" {
+#  port           = 22
+#  password_login = false
+#  authorized_keys = [
+#    file("~/.ssh/id_ed25519.pub"),
+#  ]
+#}
+
+#resource "tomato_script" "block_iot_wan" {
+#  hook    = "firewall"
+#  name    = "block_iot_wan"
+#  content = <<-EOT
+#    iptables -I FORWARD -i br1 -o vlan2 -j DROP
+#  EOT
+#}
+
+#resource "tomato_schedule" "nightly_reboot" {
+#  job  = "reboot"
+#  time = "04:30"
+#  days = ["sun", "wed"]
+#}
+
+#resource "tomato_schedule" "rotate_logs" {
+#  job     = "custom"
+#  time    = "00:05"
+#  command = "/jffs/rotate-logs.sh"
+#}
+
+#resource "tomato_time" "time" {
+#  timezone    = "WET0WEST,M3.5.0/1,M10.5.0"
+#  ntp_servers = ["0.pool.ntp.org", "1.pool.ntp.org"]
+#}
+
+#resource "tomato_syslog" "syslog" {
+#  remote_host = "10.6.4.2"
+#}
+
+#resource "tomato_upnp" "upnp" {
+#  upnp   = false
+#  natpmp = true
+#
+#  rule {
+#    action           = "allow"
+#    external_ports   = "1024-65535"
+#    internal_address = "10.6.4.0/24"
+#    internal_ports   = "1024-65535"
+#  }
+#  rule {
+#    action           = "deny"
+#    internal_address = "0.0.0.0/0"
+#  }
+#}
+
+#resource "tomato_dhcp_server" "br0" {
+#  bridge     = 0
+#  start_ip   = "10.6.4.100"
+#  end_ip     = "10.6.4.199"
+#  lease_time = 720
+#  # static_lease_time, use_internal_dns, gateway_mode and quiet are router wide, set them on bridge 0 only
+#  quiet      = true
+#  options = {
+#    "option:ntp-server" = "10.6.4.1"
+#  }
+#}
+
+#resource "tomato_ipv6" "ipv6" {
+#  service       = "native-pd"
+#  prefix_length = 60
+#  bridges       = [1]
+#}
+
+#resource "tomato_static_ip" "pxe_client"{
+#  ip       = "10.6.4.7"
+#  hostname = "PXEClient"
+#  mac      = "56:56:56:56:56:57"
+#  tags     = ["lab"]
+#  dhcp_options = {
+#    "option:router"     = "10.6.4.254"
+#    "option:dns-server" = "10.6.4.2"
+#  }
+#}
+
+#resource "tomato_pxe" "lab" {
+#  tftp_root      = "/mnt/usb/tftp"
+#  bios_boot_file = "pxelinux.0"
+#  uefi_boot_file = "grubx64.efi"
+#  tag            = "lab"
+#}
+
+#resource "tomato_adblock" "adblock" {
+#  source {
+#    url = "https://raw.githubusercontent.com/StevenBlack/hosts/master/hosts"
+#  }
+#  blocked_domains = ["ads.example.com"]
+#  allowed_domains = ["cdn.example.com"]
+#}
+
+#resource "tomato_stubby" "dot" {
+#  resolver {
+#    address  = "1.1.1.1"
+#    hostname = "cloudflare-dns.com"
+#  }
+#  resolver {
+#    address  = "9.9.9.9"
+#    hostname = "dns.quad9.net"
+#    spki_pin = "/SlsviBkb05Y/8XiKF9+CZsgCtrqPQk5bh47o0R3/Cg="
+#  }
+#  priority = "no-resolv"
+#}
+
+#data "tomato_dhcp_leases" "leases" {}
+
+#resource "tomato_static_ip" "from_lease" {
+#  for_each = { for l in data.tomato_dhcp_leases.leases.leases : l.mac => l if l.hostname != "" }
+#  mac      = each.value.mac
+#  ip       = each.value.ip
+#  hostname = each.value.hostname
+#}
+
+#data "tomato_devices" "iot" {
+#  interface = "br1"
+#}
+
+#output "iot_macs" {
+#  value = data.tomato_devices.iot.arp[*].mac
+#}
+
+#data "tomato_router_info" "info" {}
+
+#output "firmware" {
+#  value = "${data.tomato_router_info.info.model} ${data.tomato_router_info.info.firmware_version}"
+#}
+
+#data "tomato_wireless_survey" "survey" {}
+
+#resource "tomato_generic" "channel_24" {
+#  key      = "wl0_channel"
+#  value    = data.tomato_wireless_survey.survey.recommended_channels["2.4"]
+#  services = ["wireless-restart"]
+#}
+
+#data "tomato_bandwidth" "monthly" {
+#  period = "monthly"
+#}
+
+#data "tomato_ip_traffic" "laptop" {
+#  period = "daily"
+#  ip     = "10.0.0.50"
+#}
+
+#data "tomato_conntrack" "voip" {
+#  proto = "udp"
+#  port  = 5060
+#}
+
+#output "voip_classes" {
+#  value = distinct(data.tomato_conntrack.voip.connections[*].class)
+#}
+
+#data "tomato_logs" "dnsmasq" {
+#  grep      = "dnsmasq\\[[0-9]+\\]: (read|started)"
+#  max_lines = 20
+#}
+
+#data "tomato_static_ips" "all" {}
+
+#data "tomato_dns_entries" "all" {}
+
+#import {
+#  for_each = { for s in data.tomato_static_ips.all.static_ips : s.mac => s }
+#  to       = tomato_static_ip.imported[each.key]
+#  id       = each.key
+#}
+
+#data "tomato_usb_devices" "storage" {
+#  mount_point = "/tmp/mnt/data"
+#}
@@ -1,5 +1,7 @@
 #! /bin/bash
-cat > README.md << "EOT"
+DIR="$(dirname "$0")"
+
+cat > "$DIR/README.md" << "EOT"
 # Terraform Provider Tomato
 This repo contains a provider for manipulating Tomato router settings through terraform.
 
@@ -32,6 +34,6 @@ This provider exposes the following datasources and resources (see examples in .
 
 EOT
 
-for file in $(find ./docs/ -name '*.md'); do 
-  cat $file | tail --lines +8 >> ./README.md
+for file in "$DIR/docs/index.md" $(find "$DIR/docs/data-sources/" "$DIR/docs/resources/" -name '*.md' | sort); do
+  cat $file | tail --lines +8 >> "$DIR/README.md"
 done
@@ -2,7 +2,10 @@ module terraform-provider-tomato
 
 go 1.19
 
-require github.com/hashicorp/terraform-plugin-sdk/v2 v2.24.0
+require (
+	github.com/hashicorp/terraform-plugin-sdk/v2 v2.24.0
+	golang.org/x/crypto v0.0.0-20220622213112-05595931fe9d
+)
 
 require (
 	github.com/Masterminds/goutils v1.1.1 // indirect
@@ -55,7 +58,6 @@ require (
 	github.com/vmihailenco/msgpack/v4 v4.3.12 // indirect
 	github.com/vmihailenco/tagparser v0.1.1 // indirect
 	github.com/zclconf/go-cty v1.11.0 // indirect
-	golang.org/x/crypto v0.0.0-20220622213112-05595931fe9d // indirect
 	golang.org/x/net v0.0.0-20211112202133-69e39bad7dc2 // indirect
 	golang.org/x/sys v0.0.0-20220627191245-f75cf1eec38b // indirect
 	golang.org/x/text v0.3.7 // indirect
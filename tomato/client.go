@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"regexp"
+	"sort"
+	"strings"
 	"time"
 	//  "github.com/hashicorp/terraform-plugin-log/tflog"
 )
@@ -128,6 +131,27 @@ func (c *Client) applyChange(service, nvram_entries string) (string, error) {
 
 }
 
+// encode NVRAM entries as the body fragment expected by applyChange
+func encodeNVRAM(entries map[string]string) string {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+url.QueryEscape(entries[k]))
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// join service names into the _service argument expected by applyChange
+func joinServices(services ...string) string {
+	return strings.Join(services, "%2C")
+}
+
 // retrieve NVRAM
 func (c *Client) getNVRAM() (map[string]string, error) {
 	if c.Auth.Username == "" || c.Auth.Password == "" {
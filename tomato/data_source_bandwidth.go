@@ -0,0 +1,144 @@
+package tomato
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceBandwidth() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: bandwidthRead,
+		Schema: map[string]*schema.Schema{
+			"period": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "daily",
+				ValidateFunc: validation.StringInSlice([]string{"daily", "monthly"}, false),
+			},
+			// rstats has no per-interface history, the other interfaces only report their counters since boot
+			"wan_history": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"date": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"rx_kb": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"tx_kb": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"interfaces": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"rx_bytes": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"tx_bytes": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func bandwidthRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	period := d.Get("period").(string)
+
+	// rstats only keeps history for the WAN, the same data bwm-daily.asp and bwm-monthly.asp show
+	js, err := c.execCGI("bandwidth", period)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// rows are [date, rx, tx] with the totals in KB
+	rows, err := jsRows(js, period+"_history")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	history := []interface{}{}
+	for _, row := range rows {
+		history = append(history, map[string]interface{}{
+			"date":  bandwidthDate(jsInt(jsField(row, 0)), period),
+			"rx_kb": jsInt(jsField(row, 1)),
+			"tx_kb": jsInt(jsField(row, 2)),
+		})
+	}
+
+	// netdev = { 'vlan2': { rx: 0x..., tx: 0x... }, ... } holds the counters since boot
+	js, err = c.execCGI("netdev")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	v, err := jsVar(js, "netdev")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	netdev, _ := v.(map[string]interface{})
+
+	names := []string{}
+	for name := range netdev {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	interfaces := []interface{}{}
+	for _, name := range names {
+		counters, _ := netdev[name].(map[string]interface{})
+		interfaces = append(interfaces, map[string]interface{}{
+			"name":     name,
+			"rx_bytes": jsInt(counters["rx"]),
+			"tx_bytes": jsInt(counters["tx"]),
+		})
+	}
+
+	if err := d.Set("wan_history", history); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("interfaces", interfaces); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("bandwidth/" + period)
+
+	return diags
+}
+
+// history dates are packed as (year - 1900) << 16 | month << 8 | day with a zero based month
+func bandwidthDate(packed int, period string) string {
+	year := (packed>>16)&0xff + 1900
+	month := (packed>>8)&0xff + 1
+	if period == "monthly" {
+		return fmt.Sprintf("%04d-%02d", year, month)
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", year, month, packed&0xff)
+}
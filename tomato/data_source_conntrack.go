@@ -0,0 +1,181 @@
+package tomato
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// qos_classnames is empty until the QoS page has been saved once
+const conntrackDefaultClasses = "Highest High Medium Low Lowest A B C D E"
+
+func dataSourceConntrack() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: conntrackRead,
+		Schema: map[string]*schema.Schema{
+			"proto": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringInSlice([]string{"tcp", "udp", "icmp"}, false),
+			},
+			"ip": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsIPAddress,
+			},
+			"port": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ValidateFunc: validation.IsPortNumber,
+			},
+			"class": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"connections": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"proto": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"src": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"dst": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"src_port": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"dst_port": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"class": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"rule": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"bytes_out": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"bytes_in": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"timeout": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func conntrackRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	classes := strings.Fields(n["qos_classnames"])
+	if len(classes) == 0 {
+		classes = strings.Fields(conntrackDefaultClasses)
+	}
+
+	js, err := c.execCGI("ctdump")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// rows are [proto, timeout, src, dst, src port, dst port, bytes out, bytes in, class, rule]
+	// the class is 1 based with 0 for unclassified traffic and the rule is the qos_orules index
+	rows, err := jsRows(js, "ctdump")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	proto := d.Get("proto").(string)
+	ip := d.Get("ip").(string)
+	port := d.Get("port").(int)
+	class := d.Get("class").(string)
+
+	connections := []interface{}{}
+	for _, row := range rows {
+		conn := map[string]interface{}{
+			"proto":     conntrackProto(jsField(row, 0)),
+			"timeout":   jsInt(jsField(row, 1)),
+			"src":       jsString(jsField(row, 2)),
+			"dst":       jsString(jsField(row, 3)),
+			"src_port":  jsInt(jsField(row, 4)),
+			"dst_port":  jsInt(jsField(row, 5)),
+			"bytes_out": jsInt(jsField(row, 6)),
+			"bytes_in":  jsInt(jsField(row, 7)),
+			"class":     "Unclassified",
+			"rule":      jsInt(jsField(row, 9)),
+		}
+		if i := jsInt(jsField(row, 8)); i > 0 && i <= len(classes) {
+			conn["class"] = classes[i-1]
+		}
+
+		if proto != "" && proto != conn["proto"] {
+			continue
+		}
+		if ip != "" && ip != conn["src"] && ip != conn["dst"] {
+			continue
+		}
+		if port != 0 && port != conn["src_port"] && port != conn["dst_port"] {
+			continue
+		}
+		if class != "" && !strings.EqualFold(class, conn["class"].(string)) {
+			continue
+		}
+
+		connections = append(connections, conn)
+	}
+
+	if err := d.Set("connections", connections); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("conntrack")
+
+	return diags
+}
+
+// ctdump sends the protocol number, older builds send the name
+func conntrackProto(v interface{}) string {
+	switch p := jsString(v); p {
+	case "6":
+		return "tcp"
+	case "17":
+		return "udp"
+	case "1":
+		return "icmp"
+	default:
+		if _, err := strconv.Atoi(p); err == nil {
+			return p
+		}
+		return strings.ToLower(p)
+	}
+}
@@ -0,0 +1,176 @@
+package tomato
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceDevices() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: devicesRead,
+		Schema: map[string]*schema.Schema{
+			"interface": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"mac_prefix": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"arp": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"ip": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"mac": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"interface": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"wireless": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"interface": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"mac": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"rssi": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"tx_rate": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"rx_rate": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"connected_seconds": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"wds": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"interface": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"mac": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func devicesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	devlist, err := c.execCGI("devlist")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	iface := d.Get("interface").(string)
+	prefix := strings.ToLower(d.Get("mac_prefix").(string))
+	match := func(i, mac string) bool {
+		return (iface == "" || iface == i) && strings.HasPrefix(strings.ToLower(mac), prefix)
+	}
+
+	// arplist rows are [ip, mac, interface]
+	rows, err := jsRows(devlist, "arplist")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	arp := []interface{}{}
+	for _, row := range rows {
+		if !match(jsString(jsField(row, 2)), jsString(jsField(row, 1))) {
+			continue
+		}
+		arp = append(arp, map[string]interface{}{
+			"ip":        jsString(jsField(row, 0)),
+			"mac":       jsString(jsField(row, 1)),
+			"interface": jsString(jsField(row, 2)),
+		})
+	}
+
+	// wldev rows are [interface, mac, rssi, tx rate, rx rate, connected seconds, ...]
+	rows, err = jsRows(devlist, "wldev")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	wireless := []interface{}{}
+	for _, row := range rows {
+		if !match(jsString(jsField(row, 0)), jsString(jsField(row, 1))) {
+			continue
+		}
+		wireless = append(wireless, map[string]interface{}{
+			"interface":         jsString(jsField(row, 0)),
+			"mac":               jsString(jsField(row, 1)),
+			"rssi":              jsInt(jsField(row, 2)),
+			"tx_rate":           jsInt(jsField(row, 3)),
+			"rx_rate":           jsInt(jsField(row, 4)),
+			"connected_seconds": jsInt(jsField(row, 5)),
+		})
+	}
+
+	// wds rows are [interface, mac, ...], older builds do not send the list at all
+	wds := []interface{}{}
+	if rows, err = jsRows(devlist, "wds"); err == nil {
+		for _, row := range rows {
+			if !match(jsString(jsField(row, 0)), jsString(jsField(row, 1))) {
+				continue
+			}
+			wds = append(wds, map[string]interface{}{
+				"interface": jsString(jsField(row, 0)),
+				"mac":       jsString(jsField(row, 1)),
+			})
+		}
+	}
+
+	if err := d.Set("arp", arp); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("wireless", wireless); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("wds", wds); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("devices")
+
+	return diags
+}
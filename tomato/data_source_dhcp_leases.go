@@ -0,0 +1,134 @@
+package tomato
+
+import (
+	"context"
+	"net"
+	"regexp"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceDHCPLeases() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dhcpLeasesRead,
+		Schema: map[string]*schema.Schema{
+			"interface": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"leases": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"mac": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ip": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"hostname": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"lease_remaining": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"expires_in": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"interface": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dhcpLeasesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	devlist, err := c.execCGI("devlist")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// dhcpd_lease rows are [hostname, ip, mac, lease remaining]
+	rows, err := jsRows(devlist, "dhcpd_lease")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	subnets := bridgeSubnets(n)
+
+	filter := d.Get("interface").(string)
+
+	leases := []interface{}{}
+	for _, row := range rows {
+		ip := jsString(jsField(row, 1))
+		iface := bridgeForIP(subnets, ip)
+		if filter != "" && filter != iface {
+			continue
+		}
+
+		remaining := jsString(jsField(row, 3))
+		leases = append(leases, map[string]interface{}{
+			"hostname":        jsString(jsField(row, 0)),
+			"ip":              ip,
+			"mac":             jsString(jsField(row, 2)),
+			"lease_remaining": remaining,
+			"expires_in":      leaseSeconds(remaining),
+			"interface":       iface,
+		})
+	}
+
+	if err := d.Set("leases", leases); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("dhcp_leases")
+
+	return diags
+}
+
+// name of the bridge whose subnet holds ip
+func bridgeForIP(subnets map[int]*net.IPNet, ip string) string {
+	parsed := net.ParseIP(ip)
+	for bridge, subnet := range subnets {
+		if parsed != nil && subnet.Contains(parsed) {
+			return "br" + strconv.Itoa(bridge)
+		}
+	}
+	return ""
+}
+
+var leaseRemainingRe = regexp.MustCompile(`(?:([0-9]+) days?, )?([0-9]+):([0-9]+):([0-9]+)`)
+
+// seconds left on a lease shown as "1 day, 02:03:04"
+func leaseSeconds(remaining string) int {
+	match := leaseRemainingRe.FindStringSubmatch(remaining)
+	if match == nil {
+		return 0
+	}
+	days, _ := strconv.Atoi(match[1])
+	hours, _ := strconv.Atoi(match[2])
+	minutes, _ := strconv.Atoi(match[3])
+	seconds, _ := strconv.Atoi(match[4])
+	return ((days*24+hours)*60+minutes)*60 + seconds
+}
@@ -0,0 +1,60 @@
+package tomato
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceDNSEntries() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dnsEntriesRead,
+		Schema: map[string]*schema.Schema{
+			"entries": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"record": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dnsEntriesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	entries := []interface{}{}
+	for _, match := range findDNSEntries(n["dnsmasq_custom"]) {
+		entries = append(entries, map[string]interface{}{
+			"name":   match[1],
+			"record": match[2],
+		})
+	}
+
+	if err := d.Set("entries", entries); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("dns_entries")
+
+	return diags
+}
@@ -0,0 +1,100 @@
+package tomato
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+func dataSourceIPTraffic() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: ipTrafficRead,
+		Schema: map[string]*schema.Schema{
+			"period": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "daily",
+				ValidateFunc: validation.StringInSlice([]string{"daily", "monthly"}, false),
+			},
+			"ip": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsIPv4Address,
+			},
+			"history": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"date": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ip": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"rx_kb": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"tx_kb": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func ipTrafficRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	period := d.Get("period").(string)
+	filter := d.Get("ip").(string)
+
+	// cstats keeps the IP Traffic history, it is empty unless cstats_enable is set
+	js, err := c.execCGI("ipt_bandwidth", period)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// rows are [date, ip, rx, tx] with the totals in KB
+	rows, err := jsRows(js, period+"_history")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	history := []interface{}{}
+	for _, row := range rows {
+		ip := jsString(jsField(row, 1))
+		if filter != "" && filter != ip {
+			continue
+		}
+		history = append(history, map[string]interface{}{
+			"date":  bandwidthDate(jsInt(jsField(row, 0)), period),
+			"ip":    ip,
+			"rx_kb": jsInt(jsField(row, 2)),
+			"tx_kb": jsInt(jsField(row, 3)),
+		})
+	}
+
+	if err := d.Set("history", history); err != nil {
+		return diag.FromErr(err)
+	}
+
+	id := "ip_traffic/" + period
+	if filter != "" {
+		id += "/" + filter
+	}
+	d.SetId(id)
+
+	return diags
+}
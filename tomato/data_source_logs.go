@@ -0,0 +1,216 @@
+package tomato
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// Oct 19 12:52:49 router daemon.info dnsmasq[1234]: read /etc/hosts - 5 addresses
+var logLineRe = regexp.MustCompile(`^([A-Z][a-z]{2} [ 0-9][0-9] [0-9]{2}:[0-9]{2}:[0-9]{2}) (\S+) ([a-z0-9]+)\.([a-z]+) ([^:\[\s]+)(?:\[([0-9]+)\])?: ?(.*)$`)
+
+func dataSourceLogs() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: logsRead,
+		Schema: map[string]*schema.Schema{
+			"since": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsRFC3339Time,
+			},
+			"grep": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"max_lines": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"entries": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"timestamp": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"host": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"facility": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"level": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"process": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"pid": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"message": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func logsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	// builds without view.cgi still let the log be downloaded from the Logs page
+	text, err := c.getPage("logs/view.cgi?which=all&_http_id=" + c.HttpID)
+	if err != nil {
+		text, err = c.getPage("logs/syslog.txt?_http_id=" + c.HttpID)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	var since time.Time
+	if s := d.Get("since").(string); s != "" {
+		since, _ = time.Parse(time.RFC3339, s)
+	}
+	var grep *regexp.Regexp
+	if g := d.Get("grep").(string); g != "" {
+		grep, err = regexp.Compile(g)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	max_lines := d.Get("max_lines").(int)
+
+	// syslog writes the router's wall clock without a zone
+	location, err := routerLocation(c)
+	if err != nil {
+		location = time.UTC
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  "Could not work out the router's time zone, log timestamps are treated as UTC",
+			Detail:   err.Error(),
+		})
+	}
+	now := time.Now().In(location)
+
+	entries := []interface{}{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "" {
+			continue
+		}
+		if grep != nil && !grep.MatchString(line) {
+			continue
+		}
+
+		entry := map[string]interface{}{
+			"timestamp": "",
+			"host":      "",
+			"facility":  "",
+			"level":     "",
+			"process":   "",
+			"pid":       0,
+			"message":   line,
+		}
+
+		if match := logLineRe.FindStringSubmatch(line); match != nil {
+			// syslog leaves out the year
+			t, err := time.ParseInLocation("Jan _2 15:04:05", match[1], location)
+			if err == nil {
+				t = t.AddDate(now.Year(), 0, 0)
+				if t.After(now.Add(24 * time.Hour)) {
+					t = t.AddDate(-1, 0, 0)
+				}
+				if !since.IsZero() && t.Before(since) {
+					continue
+				}
+				entry["timestamp"] = t.Format(time.RFC3339)
+			}
+
+			pid, _ := strconv.Atoi(match[6])
+			entry["host"] = match[2]
+			entry["facility"] = match[3]
+			entry["level"] = match[4]
+			entry["process"] = match[5]
+			entry["pid"] = pid
+			entry["message"] = match[7]
+		}
+
+		// a line without a timestamp cannot be placed after since
+		if !since.IsZero() && entry["timestamp"] == "" {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	// keep the newest lines
+	if max_lines > 0 && len(entries) > max_lines {
+		entries = entries[len(entries)-max_lines:]
+	}
+
+	if err := d.Set("entries", entries); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("logs")
+
+	return diags
+}
+
+// the router's UTC offset, status-data.jsx reports its clock as "Mon, 19 Oct 2026 12:52:49 +0200".
+// Builds that leave out the offset are compared with UTC instead.
+func routerLocation(c *Client) (*time.Location, error) {
+	status, err := c.getPage("status-data.jsx?_http_id=" + c.HttpID)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := jsVar(status, "stats.time")
+	if err != nil {
+		return nil, err
+	}
+	clock := strings.TrimSpace(jsString(v))
+
+	if t, err := time.Parse(time.RFC1123Z, clock); err == nil {
+		_, offset := t.Zone()
+		return time.FixedZone("router", offset), nil
+	}
+
+	// drop a trailing zone name, Go would make up a zero offset for it
+	fields := strings.Fields(clock)
+	if len(fields) == 6 {
+		clock = strings.Join(fields[:5], " ")
+	}
+	t, err := time.Parse("Mon, 02 Jan 2006 15:04:05", clock)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected router clock %q", clock)
+	}
+
+	// time zones are whole quarter hours, the rest is clock drift and request latency
+	offset := t.Sub(time.Now().UTC()).Round(15 * time.Minute)
+	return time.FixedZone("router", int(offset.Seconds())), nil
+}
@@ -2,20 +2,68 @@ package tomato
 
 import (
 	"context"
-	"strconv"
-	"time"
+	"net/url"
+	"regexp"
+	"strings"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
+// keys holding passwords and private key material, they are only returned in sensitive_nvram
+var nvramSecretKeys = regexp.MustCompile(`^(` +
+	`http_passwd|ppp_passwd|pppoe_passwd|wan[0-9]*_ppp_passwd|smbd_password|` +
+	`pptpd_users|pptp_client_passwd|ftp_users|cifs[12]|` +
+	`sshd_(host|dss|ecdsa|ed25519)key|https_crt_file|ddnsx[0-9]+|` +
+	`wl[0-9.]*_(wpa_psk|key[1-4]|radius_key)|` +
+	`vpn_(server|client)[0-9]+_(key|static|crt|ca|dh|password)|vpn_server[0-9]+_users_val|` +
+	`tinc_private_(rsa|ed25519)|` +
+	`wg[0-9]+_(key|peers)` +
+	`)$`)
+
+// a %XX escape means the GUI stored the value URL encoded
+var nvramEncodedValue = regexp.MustCompile(`%[0-9A-Fa-f]{2}`)
+
 func dataSourceNVRAM() *schema.Resource {
 	return &schema.Resource{
 		ReadContext: nvramRead,
 		Schema: map[string]*schema.Schema{
+			"keys": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"prefix": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"regex": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.StringIsValidRegExp,
+			},
+			"url_decode": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
 			"nvram": &schema.Schema{
 				Type:     schema.TypeMap,
 				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"sensitive_nvram": &schema.Schema{
+				Type:      schema.TypeMap,
+				Computed:  true,
+				Sensitive: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
 			},
 		},
 	}
@@ -32,12 +80,54 @@ func nvramRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.
 		return diag.FromErr(err)
 	}
 
-	if err := d.Set("nvram", n); err != nil {
+	keys := d.Get("keys").(*schema.Set)
+	prefix := d.Get("prefix").(string)
+	// ValidateFunc skips a regex that is only known after apply, so it is compiled with an error here too
+	var re *regexp.Regexp
+	if r := d.Get("regex").(string); r != "" {
+		re, err = regexp.Compile(r)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	decode := d.Get("url_decode").(bool)
+
+	// a key is returned when it passes every filter that is set
+	nvram := make(map[string]string)
+	sensitive := make(map[string]string)
+	for k, v := range n {
+		if keys.Len() > 0 && !keys.Contains(k) {
+			continue
+		}
+		if !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		if re != nil && !re.MatchString(k) {
+			continue
+		}
+
+		// PathUnescape leaves + alone, it is common in keys and base64 values
+		if decode && nvramEncodedValue.MatchString(v) {
+			if decoded, err := url.PathUnescape(v); err == nil {
+				v = decoded
+			}
+		}
+
+		if nvramSecretKeys.MatchString(k) {
+			sensitive[k] = v
+		} else {
+			nvram[k] = v
+		}
+	}
+
+	if err := d.Set("nvram", nvram); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("sensitive_nvram", sensitive); err != nil {
 		return diag.FromErr(err)
 	}
 
-	// always run
-	d.SetId(strconv.FormatInt(time.Now().Unix(), 10))
+	d.SetId("nvram")
 
 	return diags
 }
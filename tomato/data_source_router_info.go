@@ -0,0 +1,147 @@
+package tomato
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceRouterInfo() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: routerInfoRead,
+		Schema: map[string]*schema.Schema{
+			"model": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"firmware_version": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"firmware_build": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"uptime": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"cpu_load": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeFloat,
+				},
+			},
+			"memory_total": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"memory_free": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+			"wan_ip": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"wan_gateway": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+			"wan_dns": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func routerInfoRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	status, err := c.getPage("status-data.jsx?_http_id=" + c.HttpID)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// sysinfo = { uptime: .., loads: [..], totalram: .., freeram: .., ... }
+	v, err := jsVar(status, "sysinfo")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	sysinfo, _ := v.(map[string]interface{})
+
+	// loads are fixed point with 16 fractional bits
+	cpu_load := []float64{}
+	if loads, ok := sysinfo["loads"].([]interface{}); ok {
+		for _, l := range loads {
+			cpu_load = append(cpu_load, float64(jsInt(l))/65536.0)
+		}
+	}
+
+	// os_version is the release followed by the build flavour, e.g. "2023.5 K26ARM USB AIO-64K"
+	version := strings.Fields(n["os_version"])
+	firmware_version, firmware_build := "", ""
+	if len(version) > 0 {
+		firmware_version = version[0]
+		firmware_build = strings.Join(version[1:], " ")
+	}
+
+	wan_gateway := n["wan_gateway_get"]
+	if wan_gateway == "" || wan_gateway == "0.0.0.0" {
+		wan_gateway = n["wan_gateway"]
+	}
+	wan_dns := strings.Fields(n["wan_get_dns"])
+	if len(wan_dns) == 0 {
+		wan_dns = strings.Fields(n["wan_dns"])
+	}
+
+	if err := d.Set("model", n["t_model_name"]); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("firmware_version", firmware_version); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("firmware_build", firmware_build); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("uptime", jsInt(sysinfo["uptime"])); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("cpu_load", cpu_load); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("memory_total", jsInt(sysinfo["totalram"])); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("memory_free", jsInt(sysinfo["freeram"])); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("wan_ip", n["wan_ipaddr"]); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("wan_gateway", wan_gateway); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("wan_dns", wan_dns); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("router_info")
+
+	return diags
+}
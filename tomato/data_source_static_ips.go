@@ -0,0 +1,100 @@
+package tomato
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceStaticIps() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: staticIpsRead,
+		Schema: map[string]*schema.Schema{
+			"static_ips": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"mac": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"mac2": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"hostname": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ip": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"bind": &schema.Schema{
+							Type:     schema.TypeBool,
+							Computed: true,
+						},
+						"dhcp_options": &schema.Schema{
+							Type:     schema.TypeMap,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+						"tags": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func staticIpsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	dhcp_static, err := url.QueryUnescape(n["dhcpd_static"])
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	static_ips := []interface{}{}
+	for _, entry := range staticIpEntries(dhcp_static) {
+		_, block := findManagedBlock(staticIpBlockName(entry[staticIpMAC]), n["dnsmasq_custom"])
+		tags, dhcp_options := staticIpParseOptions(entry[staticIpMAC], block)
+
+		static_ips = append(static_ips, map[string]interface{}{
+			"mac":          entry[staticIpMAC],
+			"mac2":         entry[staticIpMAC2],
+			"ip":           entry[staticIpIP],
+			"hostname":     entry[staticIpHostname],
+			"bind":         entry[staticIpBind] == "1",
+			"tags":         tags,
+			"dhcp_options": dhcp_options,
+		})
+	}
+
+	if err := d.Set("static_ips", static_ips); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("static_ips")
+
+	return diags
+}
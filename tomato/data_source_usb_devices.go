@@ -0,0 +1,178 @@
+package tomato
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceUSBDevices() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: usbDevicesRead,
+		Schema: map[string]*schema.Schema{
+			"mount_point": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"mount_points": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"devices": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"host": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"vendor": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"product": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"serial": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"partitions": &schema.Schema{
+							Type:     schema.TypeList,
+							Computed: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"disk": &schema.Schema{
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"number": &schema.Schema{
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"mounted": &schema.Schema{
+										Type:     schema.TypeBool,
+										Computed: true,
+									},
+									"mount_point": &schema.Schema{
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"filesystem": &schema.Schema{
+										Type:     schema.TypeString,
+										Computed: true,
+									},
+									"size": &schema.Schema{
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+									"free": &schema.Schema{
+										Type:     schema.TypeInt,
+										Computed: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func usbDevicesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	js, err := c.execCGI("usbdevs")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// usbdev rows are [type, host, vendor, product, serial, discs, ...]
+	// discs are [name, partitions] and partitions are [number, mounted, mount point, filesystem, size, free]
+	rows, err := jsRows(js, "usbdev")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	devices := []interface{}{}
+	mount_points := []string{}
+	for _, row := range rows {
+		partitions := []interface{}{}
+		discs, _ := jsField(row, 5).([]interface{})
+		for _, disc := range discs {
+			disc, _ := disc.([]interface{})
+			parts, _ := jsField(disc, 1).([]interface{})
+			for _, part := range parts {
+				part, ok := part.([]interface{})
+				if !ok {
+					continue
+				}
+
+				mounted := jsInt(jsField(part, 1)) != 0
+				mount_point := jsString(jsField(part, 2))
+				if mounted && mount_point != "" {
+					mount_points = append(mount_points, mount_point)
+				}
+
+				partitions = append(partitions, map[string]interface{}{
+					"disk":        jsString(jsField(disc, 0)),
+					"number":      jsInt(jsField(part, 0)),
+					"mounted":     mounted,
+					"mount_point": mount_point,
+					"filesystem":  jsString(jsField(part, 3)),
+					"size":        jsInt(jsField(part, 4)),
+					"free":        jsInt(jsField(part, 5)),
+				})
+			}
+		}
+
+		devices = append(devices, map[string]interface{}{
+			"type":       jsString(jsField(row, 0)),
+			"host":       jsString(jsField(row, 1)),
+			"vendor":     jsString(jsField(row, 2)),
+			"product":    jsString(jsField(row, 3)),
+			"serial":     jsString(jsField(row, 4)),
+			"partitions": partitions,
+		})
+	}
+
+	// fail the plan when a disk that other resources rely on is not there
+	if want := d.Get("mount_point").(string); want != "" {
+		found := false
+		for _, mount_point := range mount_points {
+			if mount_point == want {
+				found = true
+			}
+		}
+		if !found {
+			return diag.FromErr(fmt.Errorf("nothing is mounted on %s, mounted: %s", want, strings.Join(mount_points, ", ")))
+		}
+	}
+
+	if err := d.Set("devices", devices); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("mount_points", mount_points); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("usb_devices")
+
+	return diags
+}
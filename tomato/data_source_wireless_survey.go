@@ -0,0 +1,199 @@
+package tomato
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// channels that are legal everywhere and do not need DFS
+var wirelessSurveyChannels = map[string][]int{
+	"2.4": {1, 6, 11},
+	"5":   {36, 40, 44, 48, 149, 153, 157, 161, 165},
+}
+
+func dataSourceWirelessSurvey() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: wirelessSurveyRead,
+		Schema: map[string]*schema.Schema{
+			"networks": &schema.Schema{
+				Type:     schema.TypeList,
+				Computed: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"bssid": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"ssid": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"band": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+						"channel": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"width": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"rssi": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"noise": &schema.Schema{
+							Type:     schema.TypeInt,
+							Computed: true,
+						},
+						"security": &schema.Schema{
+							Type:     schema.TypeString,
+							Computed: true,
+						},
+					},
+				},
+			},
+			"recommended_channels": &schema.Schema{
+				Type:     schema.TypeMap,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeInt,
+				},
+			},
+		},
+	}
+}
+
+func wirelessSurveyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	// the radios stop serving clients for a few seconds while they scan
+	wlscan, err := c.execCGI("wlscan")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// wlscandata rows are [bssid, ssid, channel, ?, rssi, noise, capability, rates, width, band, security]
+	// older builds leave out the width, band and security columns
+	rows, err := jsRows(wlscan, "wlscandata")
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	networks := []interface{}{}
+	for _, row := range rows {
+		channel := jsInt(jsField(row, 2))
+
+		band := jsString(jsField(row, 9))
+		if band == "" {
+			band = "2.4"
+			if channel > 14 {
+				band = "5"
+			}
+		}
+
+		width := jsInt(jsField(row, 8))
+		if width == 0 {
+			width = 20
+		}
+
+		// bit 4 of the capability field is the privacy bit
+		security := jsString(jsField(row, 10))
+		if security == "" {
+			security = "open"
+			if jsInt(jsField(row, 6))&0x10 != 0 {
+				security = "encrypted"
+			}
+		}
+
+		networks = append(networks, map[string]interface{}{
+			"bssid":    jsString(jsField(row, 0)),
+			"ssid":     jsString(jsField(row, 1)),
+			"band":     band,
+			"channel":  channel,
+			"width":    width,
+			"rssi":     jsInt(jsField(row, 4)),
+			"noise":    jsInt(jsField(row, 5)),
+			"security": security,
+		})
+	}
+
+	recommended := make(map[string]int)
+	for band, channels := range wirelessSurveyChannels {
+		recommended[band] = wirelessSurveyRecommend(band, channels, networks)
+	}
+
+	if err := d.Set("networks", networks); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("recommended_channels", recommended); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("wireless_survey")
+
+	return diags
+}
+
+// pick the candidate channel with the least signal from the networks that overlap it,
+// stronger networks weigh more and ties go to the lowest channel
+func wirelessSurveyRecommend(band string, channels []int, networks []interface{}) int {
+	score := make(map[int]int)
+	for _, n := range networks {
+		network := n.(map[string]interface{})
+		if network["band"].(string) != band {
+			continue
+		}
+
+		// anything under -100dBm is lost in the noise
+		weight := network["rssi"].(int) + 100
+		if weight <= 0 {
+			weight = 1
+		}
+
+		low, high := wirelessSurveySpan(band, network["channel"].(int), network["width"].(int))
+		for _, ch := range channels {
+			if ch >= low && ch <= high {
+				score[ch] += weight
+			}
+		}
+	}
+
+	best := append([]int{}, channels...)
+	sort.SliceStable(best, func(i, j int) bool {
+		return score[best[i]] < score[best[j]]
+	})
+	return best[0]
+}
+
+// the channel numbers a network occupies, channel numbers are 5MHz apart
+func wirelessSurveySpan(band string, channel, width int) (int, int) {
+	if band == "2.4" {
+		// 20MHz channels bleed into their four neighbours on each side, 40MHz adds another four
+		spread := 4
+		if width >= 40 {
+			spread = 8
+		}
+		return channel - spread, channel + spread
+	}
+
+	// bonded 5GHz channels are aligned to blocks starting at 36 or 149
+	size := width / 5
+	if size <= 4 {
+		return channel, channel
+	}
+	base := 36
+	if channel >= 149 {
+		base = 149
+	}
+	start := base + (channel-base)/size*size
+	return start, start + size - 4
+}
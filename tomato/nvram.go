@@ -0,0 +1,203 @@
+package tomato
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+type nvramFieldType int
+
+const (
+	nvramString nvramFieldType = iota
+	nvramBool
+	nvramInt
+)
+
+// nvramField maps a schema attribute onto a single NVRAM key
+type nvramField struct {
+	attr string
+	key  string
+	kind nvramFieldType
+	// only written when set in the configuration (for Optional+Computed attributes)
+	omitEmpty bool
+}
+
+// copy the values of fields from NVRAM into the resource state
+func nvramFieldsRead(d *schema.ResourceData, n map[string]string, fields []nvramField) error {
+	for _, f := range fields {
+		value := n[f.key]
+
+		var err error
+		switch f.kind {
+		case nvramBool:
+			err = d.Set(f.attr, value == "1")
+		case nvramInt:
+			i, _ := strconv.Atoi(value)
+			err = d.Set(f.attr, i)
+		default:
+			err = d.Set(f.attr, value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// render the values of fields from the resource configuration into NVRAM entries
+func nvramFieldsWrite(d *schema.ResourceData, fields []nvramField, entries map[string]string) {
+	for _, f := range fields {
+		if f.omitEmpty {
+			if _, ok := d.GetOk(f.attr); !ok {
+				continue
+			}
+		}
+
+		switch f.kind {
+		case nvramBool:
+			entries[f.key] = boolToNVRAM(d.Get(f.attr).(bool))
+		case nvramInt:
+			entries[f.key] = strconv.Itoa(d.Get(f.attr).(int))
+		default:
+			entries[f.key] = d.Get(f.attr).(string)
+		}
+	}
+}
+
+func boolToNVRAM(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// split a CIDR into the address and dotted netmask pair NVRAM uses
+func cidrToAddrMask(cidr string) (string, string, error) {
+	ip, ipnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", "", err
+	}
+	if ip.To4() == nil {
+		return "", "", fmt.Errorf("%s is not an IPv4 network", cidr)
+	}
+	if !ip.Equal(ipnet.IP) {
+		return "", "", fmt.Errorf("%s has host bits set, use %s", cidr, ipnet)
+	}
+	return ipnet.IP.String(), net.IP(ipnet.Mask).String(), nil
+}
+
+// join an address and dotted netmask pair from NVRAM into a CIDR
+func addrMaskToCIDR(addr, mask string) string {
+	ip := net.ParseIP(addr)
+	m := net.ParseIP(mask)
+	if ip == nil || m == nil || m.To4() == nil {
+		return ""
+	}
+	ones, _ := net.IPMask(m.To4()).Size()
+	return fmt.Sprintf("%s/%d", ip.String(), ones)
+}
+
+// unit lists such as vpn_server_eas hold the comma separated numbers of the units started with the WAN
+func unitListContains(list string, unit int) bool {
+	for _, u := range strings.Split(list, ",") {
+		if strings.TrimSpace(u) == strconv.Itoa(unit) {
+			return true
+		}
+	}
+	return false
+}
+
+func updateUnitList(list string, unit int, present bool) string {
+	units := []string{}
+	for _, u := range strings.Split(list, ",") {
+		u = strings.TrimSpace(u)
+		if u == "" || u == strconv.Itoa(unit) {
+			continue
+		}
+		units = append(units, u)
+	}
+	if present {
+		units = append(units, strconv.Itoa(unit))
+	}
+	sort.Strings(units)
+	return strings.Join(units, ",")
+}
+
+// managed blocks let several resources share a multi-line NVRAM value, each owning the lines between its markers
+func renderManagedBlock(name, content string) string {
+	return fmt.Sprintf("# BEGIN terraform %s\n%s\n# END terraform %s\n", name, content, name)
+}
+
+func findManagedBlock(name, text string) (string, string) {
+	re := regexp.MustCompile(`(?s)# BEGIN terraform ` + regexp.QuoteMeta(name) + `\n(.*?)\n?# END terraform ` + regexp.QuoteMeta(name) + `(?:\n|$)`)
+
+	match := re.FindStringSubmatch(text)
+	if match == nil {
+		return "", ""
+	}
+	return match[0], match[1]
+}
+
+// split text into the lines outside any managed block and the managed blocks themselves
+func splitManagedBlocks(text string) (string, string) {
+	re := regexp.MustCompile(`(?m)^# BEGIN terraform (\S+)$`)
+
+	blocks := ""
+	for _, match := range re.FindAllStringSubmatch(text, -1) {
+		entry, _ := findManagedBlock(match[1], text)
+		if entry == "" {
+			continue
+		}
+		text = strings.Replace(text, entry, "", 1)
+		if !strings.HasSuffix(entry, "\n") {
+			entry += "\n"
+		}
+		blocks += entry
+	}
+	return text, blocks
+}
+
+// bridge N settings use the br0 key with N after the prefix, e.g. lan_ipaddr and lan1_ipaddr
+func bridgeKey(bridge int, prefix, suffix string) string {
+	if bridge == 0 {
+		return prefix + suffix
+	}
+	return fmt.Sprintf("%s%d%s", prefix, bridge, suffix)
+}
+
+// subnets of the configured LAN bridges keyed by bridge number
+func bridgeSubnets(n map[string]string) map[int]*net.IPNet {
+	subnets := make(map[int]*net.IPNet)
+	for bridge := 0; bridge <= 3; bridge++ {
+		cidr := addrMaskToCIDR(n[bridgeKey(bridge, "lan", "_ipaddr")], n[bridgeKey(bridge, "lan", "_netmask")])
+		if _, ipnet, err := net.ParseCIDR(cidr); err == nil {
+			subnets[bridge] = ipnet
+		}
+	}
+	return subnets
+}
+
+// replace the block called name in text, appending it when missing and removing it when content is empty
+func setManagedBlock(name, text, content string) string {
+	block := ""
+	if content != "" {
+		block = renderManagedBlock(name, content)
+	}
+
+	if entry, _ := findManagedBlock(name, text); entry != "" {
+		return strings.Replace(text, entry, block, -1)
+	}
+	if block == "" {
+		return text
+	}
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return text + block
+}
@@ -0,0 +1,274 @@
+package tomato
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func TestCIDRAddrMask(t *testing.T) {
+	addr, mask, err := cidrToAddrMask("10.8.0.0/24")
+	if err != nil || addr != "10.8.0.0" || mask != "255.255.255.0" {
+		t.Errorf("cidrToAddrMask = %s %s %v", addr, mask, err)
+	}
+	if got := addrMaskToCIDR(addr, mask); got != "10.8.0.0/24" {
+		t.Errorf("addrMaskToCIDR = %s", got)
+	}
+
+	for _, cidr := range []string{"fd00::/64", "10.8.0.0"} {
+		if _, _, err := cidrToAddrMask(cidr); err == nil {
+			t.Errorf("cidrToAddrMask(%s) should fail", cidr)
+		}
+	}
+	if got := addrMaskToCIDR("10.8.0.0", ""); got != "" {
+		t.Errorf("addrMaskToCIDR without a mask = %q", got)
+	}
+}
+
+func TestUnitList(t *testing.T) {
+	cases := []struct {
+		list    string
+		unit    int
+		present bool
+		want    string
+	}{
+		{"", 1, true, "1"},
+		{"2", 1, true, "1,2"},
+		{"1,2", 1, true, "1,2"},
+		{"1, 2", 1, false, "2"},
+		{"2", 1, false, "2"},
+	}
+	for _, c := range cases {
+		got := updateUnitList(c.list, c.unit, c.present)
+		if got != c.want {
+			t.Errorf("updateUnitList(%q, %d, %v) = %q, want %q", c.list, c.unit, c.present, got, c.want)
+		}
+		if unitListContains(got, c.unit) != c.present {
+			t.Errorf("unitListContains(%q, %d) != %v", got, c.unit, c.present)
+		}
+	}
+}
+
+func TestOpenVPNServerCCD(t *testing.T) {
+	ccd := []interface{}{
+		map[string]interface{}{"enabled": true, "common_name": "office", "subnet": "192.168.10.0/24", "push": true},
+		map[string]interface{}{"enabled": false, "common_name": "laptop", "subnet": "10.9.0.0/30", "push": false},
+	}
+
+	ccd_val, err := openVPNServerRenderCCD(ccd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1<office<192.168.10.0<255.255.255.0<1>0<laptop<10.9.0.0<255.255.255.252<0>"; ccd_val != want {
+		t.Errorf("openVPNServerRenderCCD = %q, want %q", ccd_val, want)
+	}
+	if got := openVPNServerParseCCD(ccd_val); !reflect.DeepEqual(got, ccd) {
+		t.Errorf("openVPNServerParseCCD = %v", got)
+	}
+}
+
+func TestOpenVPNServerCustom(t *testing.T) {
+	custom, err := openVPNServerRenderCustom([]interface{}{"192.168.50.0/24"}, "keepalive 10 120\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "push \"route 192.168.50.0 255.255.255.0\"\nkeepalive 10 120\n"; custom != want {
+		t.Errorf("openVPNServerRenderCustom = %q, want %q", custom, want)
+	}
+	routes, rest := openVPNServerParseCustom(custom)
+	if !reflect.DeepEqual(routes, []string{"192.168.50.0/24"}) || rest != "keepalive 10 120\n" {
+		t.Errorf("openVPNServerParseCustom = %v, %q", routes, rest)
+	}
+}
+
+func TestWireGuardPeerRecord(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceWireGuardPeer().Schema, map[string]interface{}{
+		"interface":   0,
+		"public_key":  "xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg=",
+		"name":        "phone",
+		"address":     "10.11.0.2/32",
+		"allowed_ips": []interface{}{"10.11.0.2/32", "192.168.50.0/24"},
+		"keepalive":   25,
+	})
+
+	record := wireguardPeerRender(d, nil)
+	peers := "0<other<<<bm90IGEgcmVhbCBrZXkgYXQgYWxsIGp1c3QgYnl0ZXM=<<10.11.0.3/32<10.11.0.3/32<0>" + record
+
+	entry, fields := wireguardPeerFindEntry("xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg=", peers)
+	if entry != record {
+		t.Fatalf("wireguardPeerFindEntry = %q, want %q", entry, record)
+	}
+	if fields[wireguardPeerName] != "phone" || fields[wireguardPeerAllowedIPs] != "10.11.0.2/32,192.168.50.0/24" || fields[wireguardPeerKeepalive] != "25" {
+		t.Errorf("unexpected fields %q", fields)
+	}
+
+	if entry, _ := wireguardPeerFindEntry("missing", peers); entry != "" {
+		t.Errorf("found %q for a missing key", entry)
+	}
+
+	fields[wireguardPeerPriv] = "1"
+	fields[wireguardPeerPrivateKey] = "cHJpdmF0ZSBrZXkgc2V0IGluIHRoZSB3ZWIgVUkgISE="
+	updated := wireguardPeerRender(d, fields)
+	if _, ufields := wireguardPeerFindEntry("xTIBA5rboUvnH4htodjb6e697QjLERt1NAB4mZqp8Dg=", updated); ufields[wireguardPeerPriv] != "1" || ufields[wireguardPeerPrivateKey] != fields[wireguardPeerPrivateKey] {
+		t.Errorf("unmanaged fields not kept in %q", updated)
+	}
+}
+
+func TestScheduleRender(t *testing.T) {
+	cases := []struct {
+		config map[string]interface{}
+		want   string
+	}{
+		{map[string]interface{}{"job": "reboot", "time": "04:30", "days": []interface{}{"sun", "sat"}}, "1,270,65"},
+		{map[string]interface{}{"job": "reconnect", "every_minutes": 60, "enabled": false}, "0,-60,127"},
+		{map[string]interface{}{"job": "custom", "time": "00:00", "command": "true", "days": []interface{}{"mon"}}, "1,0,2"},
+	}
+	for _, c := range cases {
+		d := schema.TestResourceDataRaw(t, resourceSchedule().Schema, c.config)
+		if got := scheduleRender(d); got != c.want {
+			t.Errorf("scheduleRender(%v) = %q, want %q", c.config, got, c.want)
+		}
+	}
+}
+
+func TestManagedBlocks(t *testing.T) {
+	text := "address=/router.lan/10.6.4.1"
+
+	text = setManagedBlock("pxe", text, "enable-tftp\ntftp-root=/mnt")
+	want := "address=/router.lan/10.6.4.1\n# BEGIN terraform pxe\nenable-tftp\ntftp-root=/mnt\n# END terraform pxe\n"
+	if text != want {
+		t.Fatalf("append = %q, want %q", text, want)
+	}
+
+	// a name that prefixes another must not match it
+	text = setManagedBlock("pxe-uefi", text, "dhcp-boot=x")
+	if _, content := findManagedBlock("pxe", text); content != "enable-tftp\ntftp-root=/mnt" {
+		t.Errorf("findManagedBlock(pxe) = %q", content)
+	}
+
+	text = setManagedBlock("pxe", text, "enable-tftp")
+	if _, content := findManagedBlock("pxe", text); content != "enable-tftp" {
+		t.Errorf("replace left %q", content)
+	}
+
+	text = setManagedBlock("pxe", text, "")
+	text = setManagedBlock("pxe-uefi", text, "")
+	if text != "address=/router.lan/10.6.4.1\n" {
+		t.Errorf("remove left %q", text)
+	}
+	if got := setManagedBlock("missing", text, ""); got != text {
+		t.Errorf("removing a missing block changed the text to %q", got)
+	}
+}
+
+func TestStaticIpEntries(t *testing.T) {
+	dhcpd_static := "00:11:22:33:44:55<10.6.4.20<laptop<1>AA:BB:CC:DD:EE:FF,AA:BB:CC:DD:EE:00<10.6.4.21<nas<0>"
+
+	entries := staticIpEntries(dhcpd_static)
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	if e := entries[0]; e[staticIpMAC] != "00:11:22:33:44:55" || e[staticIpMAC2] != "" || e[staticIpIP] != "10.6.4.20" || e[staticIpHostname] != "laptop" || e[staticIpBind] != "1" {
+		t.Errorf("unexpected first entry %q", e)
+	}
+	if e := entries[1]; e[staticIpMAC2] != "AA:BB:CC:DD:EE:00" || e[staticIpBind] != "0" {
+		t.Errorf("unexpected second entry %q", e)
+	}
+
+	n := map[string]string{
+		"lan_ipaddr": "10.6.4.1", "lan_netmask": "255.255.255.0",
+		"lan1_ipaddr": "10.6.5.1", "lan1_netmask": "255.255.255.0",
+	}
+	if bridge, found := staticIpBridge("10.6.5.20", n); !found || bridge != 1 {
+		t.Errorf("staticIpBridge = %d, %v, want 1, true", bridge, found)
+	}
+	if _, found := staticIpBridge("10.7.0.20", n); found {
+		t.Errorf("staticIpBridge found a bridge for an address outside every subnet")
+	}
+
+	if warnings := dhcpServerPoolWarnings(0, "10.6.4.21", "10.6.4.100", dhcpd_static); len(warnings) != 1 || warnings[0].Severity != diag.Warning {
+		t.Errorf("dhcpServerPoolWarnings = %v, want one warning for 10.6.4.21", warnings)
+	}
+}
+
+func TestStaticIpOptions(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceStaticIp().Schema, map[string]interface{}{
+		"mac":  "AA:BB:CC:DD:EE:FF",
+		"ip":   "10.6.4.21",
+		"tags": []interface{}{"iot", "nas"},
+		"dhcp_options": map[string]interface{}{
+			"option:router":     "10.6.4.254",
+			"option:dns-server": "9.9.9.9,1.1.1.1",
+		},
+	})
+
+	tags, dhcp_options := staticIpParseOptions("AA:BB:CC:DD:EE:FF", staticIpRenderOptions(d))
+	if !reflect.DeepEqual(tags, []string{"iot", "nas"}) {
+		t.Errorf("tags = %q", tags)
+	}
+	if !reflect.DeepEqual(dhcp_options, map[string]string{"option:router": "10.6.4.254", "option:dns-server": "9.9.9.9,1.1.1.1"}) {
+		t.Errorf("dhcp_options = %q", dhcp_options)
+	}
+}
+
+func TestPXE(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourcePXE().Schema, map[string]interface{}{
+		"tftp_root":      "/tmp/mnt/usb/tftp",
+		"bios_boot_file": "pxelinux.0",
+		"uefi_boot_file": "ipxe.efi",
+		"boot_server":    "10.6.4.5",
+		"tag":            "lab",
+	})
+
+	tftp_root, bios, uefi, server, tag := pxeParse(pxeRender(d))
+	got := []string{tftp_root, bios, uefi, server, tag}
+	want := []string{"/tmp/mnt/usb/tftp", "pxelinux.0", "ipxe.efi", "10.6.4.5", "lab"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pxeParse(pxeRender()) = %q, want %q", got, want)
+	}
+}
+
+func TestFindDNSEntries(t *testing.T) {
+	dnsmasq_custom := "address=/potato.com/127.0.0.1\nserver=/lan/10.6.4.1\naddress=/nas.lan/10.6.4.21"
+
+	entries := findDNSEntries(dnsmasq_custom)
+	if len(entries) != 2 || entries[1][1] != "nas.lan" || entries[1][2] != "10.6.4.21" {
+		t.Errorf("findDNSEntries = %q", entries)
+	}
+	if entry, name, record := findDNSEntry("potato.com", dnsmasq_custom); entry != "address=/potato.com/127.0.0.1" || name != "potato.com" || record != "127.0.0.1" {
+		t.Errorf("findDNSEntry = %q %q %q", entry, name, record)
+	}
+}
+
+func TestCIDRHostBits(t *testing.T) {
+	if _, _, err := cidrToAddrMask("10.8.0.1/24"); err == nil {
+		t.Errorf("cidrToAddrMask should reject host bits")
+	}
+	if _, err := openVPNServerRenderCCD([]interface{}{map[string]interface{}{"enabled": true, "common_name": "x", "subnet": "192.168.10.1/24", "push": true}}); err == nil {
+		t.Errorf("a CCD subnet with host bits should fail")
+	}
+}
+
+func TestSplitManagedBlocks(t *testing.T) {
+	text := "echo one\n" + renderManagedBlock("vpn", "echo vpn") + "echo two\n" + renderManagedBlock("vpn-up", "echo up")
+
+	rest, blocks := splitManagedBlocks(text)
+	if rest != "echo one\necho two\n" {
+		t.Errorf("splitManagedBlocks rest = %q", rest)
+	}
+	if blocks != renderManagedBlock("vpn", "echo vpn")+renderManagedBlock("vpn-up", "echo up") {
+		t.Errorf("splitManagedBlocks blocks = %q", blocks)
+	}
+}
+
+func TestScriptKeepFragments(t *testing.T) {
+	current := "echo old\n" + renderManagedBlock("vpn", "echo vpn")
+	if got := scriptKeepFragments("echo new", current); got != "echo new\n"+renderManagedBlock("vpn", "echo vpn") {
+		t.Errorf("scriptKeepFragments = %q", got)
+	}
+	if got := scriptKeepFragments("echo new", "echo old"); got != "echo new" {
+		t.Errorf("scriptKeepFragments without fragments = %q", got)
+	}
+}
@@ -55,12 +55,39 @@ func Provider() *schema.Provider {
 			},
 		},
 		ResourcesMap: map[string]*schema.Resource{
-			"tomato_dns_entry": resourceDNSEntry(),
-			"tomato_static_ip": resourceStaticIp(),
-			"tomato_generic":   resourceGeneric(),
+			"tomato_dns_entry":           resourceDNSEntry(),
+			"tomato_static_ip":           resourceStaticIp(),
+			"tomato_generic":             resourceGeneric(),
+			"tomato_openvpn_server":      resourceOpenVPNServer(),
+			"tomato_openvpn_client":      resourceOpenVPNClient(),
+			"tomato_wireguard_interface": resourceWireGuardInterface(),
+			"tomato_wireguard_peer":      resourceWireGuardPeer(),
+			"tomato_admin_access":        resourceAdminAccess(),
+			"tomato_ssh":                 resourceSSH(),
+			"tomato_script":              resourceScript(),
+			"tomato_schedule":            resourceSchedule(),
+			"tomato_time":                resourceTime(),
+			"tomato_syslog":              resourceSyslog(),
+			"tomato_upnp":                resourceUPnP(),
+			"tomato_dhcp_server":         resourceDHCPServer(),
+			"tomato_ipv6":                resourceIPv6(),
+			"tomato_pxe":                 resourcePXE(),
+			"tomato_adblock":             resourceAdblock(),
+			"tomato_stubby":              resourceStubby(),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			"tomato_nvram": dataSourceNVRAM(),
+			"tomato_nvram":           dataSourceNVRAM(),
+			"tomato_dhcp_leases":     dataSourceDHCPLeases(),
+			"tomato_devices":         dataSourceDevices(),
+			"tomato_router_info":     dataSourceRouterInfo(),
+			"tomato_wireless_survey": dataSourceWirelessSurvey(),
+			"tomato_bandwidth":       dataSourceBandwidth(),
+			"tomato_ip_traffic":      dataSourceIPTraffic(),
+			"tomato_conntrack":       dataSourceConntrack(),
+			"tomato_logs":            dataSourceLogs(),
+			"tomato_static_ips":      dataSourceStaticIps(),
+			"tomato_dns_entries":     dataSourceDNSEntries(),
+			"tomato_usb_devices":     dataSourceUSBDevices(),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
@@ -0,0 +1,188 @@
+package tomato
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var adblockLock = &sync.Mutex{}
+
+var adblockDomainRe = regexp.MustCompile(`^([a-zA-Z0-9_]([a-zA-Z0-9_-]*[a-zA-Z0-9_])?\.)*[a-zA-Z0-9_]([a-zA-Z0-9_-]*[a-zA-Z0-9_])?$`)
+
+func resourceAdblock() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceAdblockCreate,
+		ReadContext:   resourceAdblockRead,
+		UpdateContext: resourceAdblockUpdate,
+		DeleteContext: resourceAdblockDelete,
+		Schema: map[string]*schema.Schema{
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"source": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"url": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.IsURLWithHTTPorHTTPS,
+						},
+						"enabled": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+					},
+				},
+			},
+			"blocked_domains": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(adblockDomainRe, "must be a domain name"),
+				},
+			},
+			"allowed_domains": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(adblockDomainRe, "must be a domain name"),
+				},
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceAdblockCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	adblockLock.Lock()
+	defer adblockLock.Unlock()
+
+	// adblock_blacklist holds enabled<url> records
+	sources := ""
+	for _, s := range d.Get("source").([]interface{}) {
+		source := s.(map[string]interface{})
+		sources += fmt.Sprintf("%s<%s>", boolToNVRAM(source["enabled"].(bool)), source["url"].(string))
+	}
+
+	entries := map[string]string{
+		"adblock_enable":           boolToNVRAM(d.Get("enabled").(bool)),
+		"adblock_blacklist":        sources,
+		"adblock_blacklist_custom": adblockJoinDomains(d.Get("blocked_domains").(*schema.Set)),
+		"adblock_whitelist":        adblockJoinDomains(d.Get("allowed_domains").(*schema.Set)),
+	}
+
+	b, err := c.applyChange("adblock-restart", encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("adblock")
+
+	resourceAdblockRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceAdblockRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	sources := []interface{}{}
+	for _, record := range strings.Split(n["adblock_blacklist"], ">") {
+		fields := strings.Split(record, "<")
+		if len(fields) < 2 {
+			continue
+		}
+		sources = append(sources, map[string]interface{}{
+			"enabled": fields[0] == "1",
+			"url":     fields[1],
+		})
+	}
+
+	if err := d.Set("enabled", n["adblock_enable"] == "1"); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("source", sources); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("blocked_domains", strings.Fields(n["adblock_blacklist_custom"])); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("allowed_domains", strings.Fields(n["adblock_whitelist"])); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("adblock")
+	return diags
+}
+
+func resourceAdblockUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := resourceAdblockCreate(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	return resourceAdblockRead(ctx, d, m)
+}
+
+func resourceAdblockDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	c := m.(*Client)
+
+	adblockLock.Lock()
+	defer adblockLock.Unlock()
+
+	// turn the blocker off, the lists are kept for the GUI
+	b, err := c.applyChange("adblock-restart", encodeNVRAM(map[string]string{"adblock_enable": "0"}))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+// the custom lists are space separated domains
+func adblockJoinDomains(set *schema.Set) string {
+	domains := []string{}
+	for _, domain := range set.List() {
+		domains = append(domains, domain.(string))
+	}
+	sort.Strings(domains)
+	return strings.Join(domains, " ")
+}
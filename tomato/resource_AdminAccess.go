@@ -0,0 +1,249 @@
+package tomato
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var adminAccessLock = &sync.Mutex{}
+
+func resourceAdminAccess() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceAdminAccessCreate,
+		ReadContext:   resourceAdminAccessRead,
+		UpdateContext: resourceAdminAccessUpdate,
+		DeleteContext: resourceAdminAccessDelete,
+		CustomizeDiff: resourceAdminAccessCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"http_enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"https_enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"http_port": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      80,
+				ValidateFunc: validation.IsPortNumber,
+			},
+			"https_port": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      443,
+				ValidateFunc: validation.IsPortNumber,
+			},
+			"remote_management": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"remote_port": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      8080,
+				ValidateFunc: validation.IsPortNumber,
+			},
+			"remote_https": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"remote_allowed_ips": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"web_css": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"username": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"password": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Computed:  true,
+				Sensitive: true,
+			},
+			"allow_lockout": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+var adminAccessFields = []nvramField{
+	{attr: "http_enabled", key: "http_enable", kind: nvramBool},
+	{attr: "https_enabled", key: "https_enable", kind: nvramBool},
+	{attr: "http_port", key: "http_lanport", kind: nvramInt},
+	{attr: "https_port", key: "https_lanport", kind: nvramInt},
+	{attr: "remote_management", key: "remote_management", kind: nvramBool},
+	{attr: "remote_port", key: "http_wanport", kind: nvramInt},
+	{attr: "remote_https", key: "remote_mgt_https", kind: nvramBool},
+	{attr: "web_css", key: "web_css", kind: nvramString, omitEmpty: true},
+	{attr: "username", key: "http_username", kind: nvramString, omitEmpty: true},
+	{attr: "password", key: "http_passwd", kind: nvramString, omitEmpty: true},
+}
+
+// refuse plans that would turn off the protocol or port the provider talks to
+func resourceAdminAccessCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Get("allow_lockout").(bool) {
+		return nil
+	}
+
+	c, ok := m.(*Client)
+	if !ok || c.HostURL == "" {
+		return nil
+	}
+
+	u, err := url.Parse(c.HostURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil
+	}
+
+	port := 80
+	if u.Scheme == "https" {
+		port = 443
+	}
+	if u.Port() != "" {
+		port, _ = strconv.Atoi(u.Port())
+	}
+
+	// the router may be managed through the WAN, tell that apart by which of the current settings serve the URL
+	n, err := c.getNVRAM()
+	if err != nil {
+		return err
+	}
+	lan := n[u.Scheme+"_enable"] == "1" && n[u.Scheme+"_lanport"] == strconv.Itoa(port)
+	remote := n["remote_management"] == "1" && (n["remote_mgt_https"] == "1") == (u.Scheme == "https") && n["http_wanport"] == strconv.Itoa(port)
+
+	if remote && !lan {
+		if !d.Get("remote_management").(bool) {
+			return fmt.Errorf("remote_management = false would lock the provider out of %s, set allow_lockout = true to apply anyway", c.HostURL)
+		}
+		if d.Get("remote_https").(bool) != (u.Scheme == "https") {
+			return fmt.Errorf("remote_https = %t would lock the provider out of %s, set allow_lockout = true to apply anyway", d.Get("remote_https").(bool), c.HostURL)
+		}
+		if configured := d.Get("remote_port").(int); configured != port {
+			return fmt.Errorf("remote_port = %d would lock the provider out of %s, set allow_lockout = true to apply anyway", configured, c.HostURL)
+		}
+		return nil
+	}
+
+	if !d.Get(u.Scheme + "_enabled").(bool) {
+		return fmt.Errorf("%s_enabled = false would lock the provider out of %s, set allow_lockout = true to apply anyway", u.Scheme, c.HostURL)
+	}
+	if configured := d.Get(u.Scheme + "_port").(int); configured != port {
+		return fmt.Errorf("%s_port = %d would lock the provider out of %s, set allow_lockout = true to apply anyway", u.Scheme, configured, c.HostURL)
+	}
+
+	return nil
+}
+
+func resourceAdminAccessCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	adminAccessLock.Lock()
+	defer adminAccessLock.Unlock()
+
+	entries := make(map[string]string)
+	nvramFieldsWrite(d, adminAccessFields, entries)
+
+	allowed_ips := []string{}
+	for _, ip := range d.Get("remote_allowed_ips").([]interface{}) {
+		allowed_ips = append(allowed_ips, ip.(string))
+	}
+	entries["rmgt_sip"] = strings.Join(allowed_ips, ",")
+
+	b, err := c.applyChange("admin-restart", encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// keep talking to the router with the new credentials
+	if username, ok := entries["http_username"]; ok {
+		c.Auth.Username = username
+	}
+	if password, ok := entries["http_passwd"]; ok {
+		c.Auth.Password = password
+	}
+
+	d.SetId("admin_access")
+
+	resourceAdminAccessRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceAdminAccessRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := nvramFieldsRead(d, n, adminAccessFields); err != nil {
+		return diag.FromErr(err)
+	}
+
+	allowed_ips := []string{}
+	for _, ip := range strings.Split(n["rmgt_sip"], ",") {
+		if ip = strings.TrimSpace(ip); ip != "" {
+			allowed_ips = append(allowed_ips, ip)
+		}
+	}
+	if err := d.Set("remote_allowed_ips", allowed_ips); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("admin_access")
+	return diags
+}
+
+func resourceAdminAccessUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := resourceAdminAccessCreate(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	return resourceAdminAccessRead(ctx, d, m)
+}
+
+func resourceAdminAccessDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// management access is left as it is, removing it from the router would lock everyone out
+	var diags diag.Diagnostics
+	return diags
+}
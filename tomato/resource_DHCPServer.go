@@ -0,0 +1,363 @@
+package tomato
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var dhcpServerLock = &sync.Mutex{}
+
+func resourceDHCPServer() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceDHCPServerCreate,
+		ReadContext:   resourceDHCPServerRead,
+		UpdateContext: resourceDHCPServerUpdate,
+		DeleteContext: resourceDHCPServerDelete,
+		CustomizeDiff: resourceDHCPServerCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"bridge": &schema.Schema{
+				Type:         schema.TypeInt,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntBetween(0, 3),
+			},
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"start_ip": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsIPv4Address,
+			},
+			"end_ip": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsIPv4Address,
+			},
+			"lease_time": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1440,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"static_lease_time": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntAtLeast(-1),
+			},
+			"use_internal_dns": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+			"gateway_mode": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+			"quiet": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Computed: true,
+			},
+			"options": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"subnet": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func dhcpServerFields(bridge int) []nvramField {
+	return []nvramField{
+		{attr: "start_ip", key: bridgeKey(bridge, "dhcpd", "_startip"), kind: nvramString},
+		{attr: "end_ip", key: bridgeKey(bridge, "dhcpd", "_endip"), kind: nvramString},
+		{attr: "lease_time", key: bridgeKey(bridge, "dhcp", "_lease"), kind: nvramInt},
+	}
+}
+
+// dhcpd_slt, dhcpd_dmdns, dhcpd_gwmode and the dnsmasq_q quiet bit are shared by all bridges,
+// only the br0 resource writes them and the others report the router wide value
+var dhcpServerGlobalFields = []nvramField{
+	{attr: "static_lease_time", key: "dhcpd_slt", kind: nvramInt},
+	{attr: "use_internal_dns", key: "dhcpd_dmdns", kind: nvramBool},
+	{attr: "gateway_mode", key: "dhcpd_gwmode", kind: nvramBool},
+}
+
+func resourceDHCPServerCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	bridge := d.Get("bridge").(int)
+	if bridge == 0 {
+		return nil
+	}
+
+	config := d.GetRawConfig()
+	for _, attr := range []string{"static_lease_time", "use_internal_dns", "gateway_mode", "quiet"} {
+		if !config.GetAttr(attr).IsNull() {
+			return fmt.Errorf("%s is shared by all bridges and can only be set on the bridge 0 server, not br%d", attr, bridge)
+		}
+	}
+	return nil
+}
+
+func resourceDHCPServerCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	dhcpServerLock.Lock()
+	defer dhcpServerLock.Unlock()
+	DNSEntryLock.Lock()
+	defer DNSEntryLock.Unlock()
+
+	bridge := d.Get("bridge").(int)
+	start := d.Get("start_ip").(string)
+	end := d.Get("end_ip").(string)
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	subnet, ok := bridgeSubnets(n)[bridge]
+	if !ok {
+		return diag.FromErr(fmt.Errorf("br%d has no LAN address configured", bridge))
+	}
+	if !subnet.Contains(net.ParseIP(start)) || !subnet.Contains(net.ParseIP(end)) {
+		return diag.FromErr(fmt.Errorf("the pool %s-%s is outside the br%d subnet %s", start, end, bridge, subnet))
+	}
+	if ipToUint32(start) > ipToUint32(end) {
+		return diag.FromErr(fmt.Errorf("start_ip %s is after end_ip %s", start, end))
+	}
+
+	entries := make(map[string]string)
+	nvramFieldsWrite(d, dhcpServerFields(bridge), entries)
+
+	proto := "static"
+	if d.Get("enabled").(bool) {
+		proto = "dhcp"
+	}
+	entries[bridgeKey(bridge, "lan", "_proto")] = proto
+
+	// the shared settings are left as they are unless the configuration sets them
+	if bridge == 0 {
+		config := d.GetRawConfig()
+		for _, f := range dhcpServerGlobalFields {
+			if !config.GetAttr(f.attr).IsNull() {
+				nvramFieldsWrite(d, []nvramField{f}, entries)
+			}
+		}
+
+		// dnsmasq_q is a bitmask, 1 silences the DHCP log lines
+		if !config.GetAttr("quiet").IsNull() {
+			q, _ := strconv.Atoi(n["dnsmasq_q"])
+			if d.Get("quiet").(bool) {
+				q |= 1
+			} else {
+				q &^= 1
+			}
+			entries["dnsmasq_q"] = strconv.Itoa(q)
+		}
+	}
+
+	entries["dnsmasq_custom"] = setManagedBlock(dhcpServerBlockName(bridge), n["dnsmasq_custom"], dhcpServerRenderOptions(bridge, d.Get("options").(map[string]interface{})))
+
+	b, err := c.applyChange(joinServices("dhcpd-restart", "dnsmasq-restart"), encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.Itoa(bridge))
+
+	// only warned about on apply, a refresh must not repeat it for every reservation
+	diags = append(diags, dhcpServerPoolWarnings(bridge, start, end, n["dhcpd_static"])...)
+
+	return append(diags, resourceDHCPServerRead(ctx, d, m)...)
+}
+
+func resourceDHCPServerRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	bridge, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	subnet, ok := bridgeSubnets(n)[bridge]
+	if !ok {
+		d.SetId("")
+		return diags
+	}
+
+	if err := d.Set("bridge", bridge); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := nvramFieldsRead(d, n, dhcpServerFields(bridge)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := nvramFieldsRead(d, n, dhcpServerGlobalFields); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("enabled", n[bridgeKey(bridge, "lan", "_proto")] == "dhcp"); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("subnet", subnet.String()); err != nil {
+		return diag.FromErr(err)
+	}
+
+	q, _ := strconv.Atoi(n["dnsmasq_q"])
+	if err := d.Set("quiet", q&1 != 0); err != nil {
+		return diag.FromErr(err)
+	}
+
+	_, block := findManagedBlock(dhcpServerBlockName(bridge), n["dnsmasq_custom"])
+	if err := d.Set("options", dhcpServerParseOptions(bridge, block)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceDHCPServerUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	return resourceDHCPServerCreate(ctx, d, m)
+}
+
+func resourceDHCPServerDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	c := m.(*Client)
+
+	dhcpServerLock.Lock()
+	defer dhcpServerLock.Unlock()
+	DNSEntryLock.Lock()
+	defer DNSEntryLock.Unlock()
+
+	bridge, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// the pool is left as it is so clients keep getting leases, only the custom options are removed
+	dnsmasq_custom := n["dnsmasq_custom"]
+	if entry, _ := findManagedBlock(dhcpServerBlockName(bridge), dnsmasq_custom); len(entry) == 0 {
+		return diags
+	}
+
+	dnsmasq_custom = setManagedBlock(dhcpServerBlockName(bridge), dnsmasq_custom, "")
+
+	b, err := c.applyChange("dnsmasq-restart", encodeNVRAM(map[string]string{"dnsmasq_custom": dnsmasq_custom}))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+// reservations handed out from the dynamic pool can clash with leases dnsmasq gives to other clients
+func dhcpServerPoolWarnings(bridge int, start, end, dhcpd_static string) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	dhcpd_static, err := url.QueryUnescape(dhcpd_static)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	for _, entry := range staticIpEntries(dhcpd_static) {
+		ip := ipToUint32(entry[staticIpIP])
+		if ip >= ipToUint32(start) && ip <= ipToUint32(end) {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Static IP %s for %s is inside the br%d dynamic pool", entry[staticIpIP], entry[staticIpMAC], bridge),
+				Detail:   fmt.Sprintf("Reservations should sit outside %s-%s so dnsmasq cannot lease the address to another client.", start, end),
+			})
+		}
+	}
+	return diags
+}
+
+func dhcpServerBlockName(bridge int) string {
+	return fmt.Sprintf("dhcp-options-br%d", bridge)
+}
+
+// dnsmasq tags every request with the name of the interface it arrived on
+func dhcpServerRenderOptions(bridge int, options map[string]interface{}) string {
+	keys := make([]string, 0, len(options))
+	for k := range options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	lines := []string{}
+	for _, k := range keys {
+		lines = append(lines, fmt.Sprintf("dhcp-option=tag:br%d,%s,%s", bridge, k, options[k].(string)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func dhcpServerParseOptions(bridge int, block string) map[string]string {
+	prefix := fmt.Sprintf("dhcp-option=tag:br%d,", bridge)
+
+	options := make(map[string]string)
+	for _, line := range strings.Split(block, "\n") {
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		kv := strings.SplitN(strings.TrimPrefix(line, prefix), ",", 2)
+		if len(kv) == 2 {
+			options[kv[0]] = kv[1]
+		}
+	}
+	return options
+}
+
+func ipToUint32(ip string) uint32 {
+	parsed := net.ParseIP(ip).To4()
+	if parsed == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint32(parsed)
+}
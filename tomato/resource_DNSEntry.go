@@ -108,9 +108,7 @@ func resourceDNSEntryRead(ctx context.Context, d *schema.ResourceData, m interfa
 }
 
 func findDNSEntry(name, dnsmasq_config string) (string, string, string) {
-	re := regexp.MustCompile(`(?m)address=/([a-zA-Z-\.]+)/([0-9]+\.[0-9]+\.[0-9]+\.[0-9]+)$`)
-
-	matches := re.FindAllStringSubmatch(dnsmasq_config, -1)
+	matches := findDNSEntries(dnsmasq_config)
 	for i := range matches {
 		if matches[i][1] == name {
 			return matches[i][0], matches[i][1], matches[i][2]
@@ -119,6 +117,13 @@ func findDNSEntry(name, dnsmasq_config string) (string, string, string) {
 	return "", "", ""
 }
 
+// every address record as [entry, name, record]
+func findDNSEntries(dnsmasq_config string) [][]string {
+	re := regexp.MustCompile(`(?m)address=/([a-zA-Z-\.]+)/([0-9]+\.[0-9]+\.[0-9]+\.[0-9]+)$`)
+
+	return re.FindAllStringSubmatch(dnsmasq_config, -1)
+}
+
 func resourceDNSEntryUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 
 	c := m.(*Client)
@@ -0,0 +1,287 @@
+package tomato
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var ipv6Lock = &sync.Mutex{}
+
+// ipv6_service is empty when IPv6 is disabled
+var ipv6Services = []string{"disabled", "native", "native-pd", "6rd", "6rd-pd", "6to4", "other"}
+
+func resourceIPv6() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceIPv6Create,
+		ReadContext:   resourceIPv6Read,
+		UpdateContext: resourceIPv6Update,
+		DeleteContext: resourceIPv6Delete,
+		CustomizeDiff: resourceIPv6CustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"service": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.StringInSlice(ipv6Services, false),
+			},
+			"prefix": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsIPv6Address,
+			},
+			"prefix_length": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      64,
+				ValidateFunc: validation.IntBetween(3, 127),
+			},
+			"router_address": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsIPv6Address,
+			},
+			"relay": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "192.88.99.1",
+				ValidateFunc: validation.IsIPv4Address,
+			},
+			"tunnel_interface": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"sixrd": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"prefix": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.IsIPv6Address,
+						},
+						"prefix_length": &schema.Schema{
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntBetween(1, 64),
+						},
+						"border_relay": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.IsIPv4Address,
+						},
+						"ipv4_mask_length": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      0,
+							ValidateFunc: validation.IntBetween(0, 32),
+						},
+					},
+				},
+			},
+			"radvd": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"dhcpd": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"lease_time": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      12,
+				ValidateFunc: validation.IntAtLeast(1),
+			},
+			"bridges": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeInt,
+					ValidateFunc: validation.IntBetween(1, 3),
+				},
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+var ipv6Fields = []nvramField{
+	{attr: "prefix", key: "ipv6_prefix", kind: nvramString},
+	{attr: "prefix_length", key: "ipv6_prefix_length", kind: nvramInt},
+	{attr: "router_address", key: "ipv6_rtr_addr", kind: nvramString},
+	{attr: "relay", key: "ipv6_relay", kind: nvramString},
+	{attr: "tunnel_interface", key: "ipv6_ifname", kind: nvramString},
+	{attr: "radvd", key: "ipv6_radvd", kind: nvramBool},
+	{attr: "dhcpd", key: "ipv6_dhcpd", kind: nvramBool},
+	{attr: "lease_time", key: "ipv6_lease_time", kind: nvramInt},
+}
+
+// each mode needs a different set of fields, the rest are ignored by the router.
+// Values that are not known yet are checked again on the apply plan
+func resourceIPv6CustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.NewValueKnown("service") {
+		return nil
+	}
+	service := d.Get("service").(string)
+
+	if d.NewValueKnown("prefix") {
+		switch service {
+		case "native", "other":
+			if d.Get("prefix").(string) == "" {
+				return fmt.Errorf("prefix is required when service is %s", service)
+			}
+		case "native-pd", "6rd-pd":
+			if d.Get("prefix").(string) != "" {
+				return fmt.Errorf("prefix is delegated by the ISP when service is %s, leave it unset", service)
+			}
+		}
+	}
+
+	if d.NewValueKnown("sixrd") {
+		sixrd := len(d.Get("sixrd").([]interface{})) > 0
+		if (service == "6rd") != sixrd {
+			if sixrd {
+				return fmt.Errorf("sixrd can only be set when service is 6rd")
+			}
+			return fmt.Errorf("sixrd is required when service is 6rd")
+		}
+	}
+	if service == "other" && d.NewValueKnown("tunnel_interface") && d.Get("tunnel_interface").(string) == "" {
+		return fmt.Errorf("tunnel_interface is required when service is other")
+	}
+
+	return nil
+}
+
+func resourceIPv6Create(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	ipv6Lock.Lock()
+	defer ipv6Lock.Unlock()
+
+	entries := make(map[string]string)
+	nvramFieldsWrite(d, ipv6Fields, entries)
+
+	service := d.Get("service").(string)
+	if service == "disabled" {
+		service = ""
+	}
+	entries["ipv6_service"] = service
+
+	if sixrd := d.Get("sixrd").([]interface{}); len(sixrd) > 0 {
+		s := sixrd[0].(map[string]interface{})
+		entries["ipv6_6rd_prefix"] = s["prefix"].(string)
+		entries["ipv6_6rd_prefix_length"] = strconv.Itoa(s["prefix_length"].(int))
+		entries["ipv6_6rd_borderrelay"] = s["border_relay"].(string)
+		entries["ipv6_6rd_ipv4masklen"] = strconv.Itoa(s["ipv4_mask_length"].(int))
+	}
+
+	// ipv6_vlan is a bitmask of the bridges br1 to br3 that get their own /64
+	vlan := 0
+	for _, bridge := range d.Get("bridges").(*schema.Set).List() {
+		vlan |= 1 << (bridge.(int) - 1)
+	}
+	entries["ipv6_vlan"] = strconv.Itoa(vlan)
+
+	b, err := c.applyChange(joinServices("wan-restart", "dnsmasq-restart"), encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("ipv6")
+
+	resourceIPv6Read(ctx, d, m)
+
+	return diags
+}
+
+func resourceIPv6Read(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := nvramFieldsRead(d, n, ipv6Fields); err != nil {
+		return diag.FromErr(err)
+	}
+
+	service := n["ipv6_service"]
+	if service == "" {
+		service = "disabled"
+	}
+	if err := d.Set("service", service); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// a delegated prefix is written back by the router, it is not part of the configuration
+	if service == "native-pd" || service == "6rd-pd" {
+		if err := d.Set("prefix", ""); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	sixrd := []interface{}{}
+	if service == "6rd" {
+		prefix_length, _ := strconv.Atoi(n["ipv6_6rd_prefix_length"])
+		mask_length, _ := strconv.Atoi(n["ipv6_6rd_ipv4masklen"])
+		sixrd = append(sixrd, map[string]interface{}{
+			"prefix":           n["ipv6_6rd_prefix"],
+			"prefix_length":    prefix_length,
+			"border_relay":     n["ipv6_6rd_borderrelay"],
+			"ipv4_mask_length": mask_length,
+		})
+	}
+	if err := d.Set("sixrd", sixrd); err != nil {
+		return diag.FromErr(err)
+	}
+
+	vlan, _ := strconv.Atoi(n["ipv6_vlan"])
+	bridges := []interface{}{}
+	for bridge := 1; bridge <= 3; bridge++ {
+		if vlan&(1<<(bridge-1)) != 0 {
+			bridges = append(bridges, bridge)
+		}
+	}
+	if err := d.Set("bridges", bridges); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("ipv6")
+	return diags
+}
+
+func resourceIPv6Update(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := resourceIPv6Create(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	return resourceIPv6Read(ctx, d, m)
+}
+
+func resourceIPv6Delete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// IPv6 is left configured, dropping it would cut off every client using it
+	var diags diag.Diagnostics
+	return diags
+}
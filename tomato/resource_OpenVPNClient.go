@@ -0,0 +1,360 @@
+package tomato
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var openVPNClientLock = &sync.Mutex{}
+
+// values of vpn_clientN_rgw
+var openVPNClientRedirectGateway = []string{"none", "all", "policy", "policy_strict"}
+
+// rule types of vpn_clientN_routing_val
+var openVPNClientRoutingTypes = []string{"", "source", "destination", "domain"}
+
+func resourceOpenVPNClient() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceOpenVPNClientCreate,
+		ReadContext:   resourceOpenVPNClientRead,
+		UpdateContext: resourceOpenVPNClientUpdate,
+		DeleteContext: resourceOpenVPNClientDelete,
+		Schema: map[string]*schema.Schema{
+			"client": &schema.Schema{
+				Type:         schema.TypeInt,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntBetween(1, 3),
+			},
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"interface_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "tun",
+				ValidateFunc: validation.StringInSlice([]string{"tun", "tap"}, false),
+			},
+			"protocol": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "udp",
+				ValidateFunc: validation.StringInSlice([]string{"udp", "tcp-client", "udp4", "tcp4-client", "udp6", "tcp6-client"}, false),
+			},
+			"server_address": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"port": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1194,
+				ValidateFunc: validation.IsPortNumber,
+			},
+			"cipher": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"crypt": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "tls",
+				ValidateFunc: validation.StringInSlice([]string{"tls", "secret", "custom"}, false),
+			},
+			"username": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"password": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"username_only": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"redirect_gateway": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "none",
+				ValidateFunc: validation.StringInSlice(openVPNClientRedirectGateway, false),
+			},
+			"nat": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"custom": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"ca": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"cert": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"key": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"static_key": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"routing_policy": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"type": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice(openVPNClientRoutingTypes[1:], false),
+						},
+						"value": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"enabled": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+					},
+				},
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func openVPNClientFields(client int) []nvramField {
+	prefix := fmt.Sprintf("vpn_client%d_", client)
+	return []nvramField{
+		{attr: "interface_type", key: prefix + "if", kind: nvramString},
+		{attr: "protocol", key: prefix + "proto", kind: nvramString},
+		{attr: "server_address", key: prefix + "addr", kind: nvramString},
+		{attr: "port", key: prefix + "port", kind: nvramInt},
+		{attr: "cipher", key: prefix + "cipher", kind: nvramString, omitEmpty: true},
+		{attr: "crypt", key: prefix + "crypt", kind: nvramString},
+		{attr: "username", key: prefix + "username", kind: nvramString},
+		{attr: "password", key: prefix + "password", kind: nvramString},
+		{attr: "username_only", key: prefix + "useronly", kind: nvramBool},
+		{attr: "nat", key: prefix + "nat", kind: nvramBool},
+		{attr: "custom", key: prefix + "custom", kind: nvramString},
+		{attr: "ca", key: prefix + "ca", kind: nvramString},
+		{attr: "cert", key: prefix + "crt", kind: nvramString},
+		{attr: "key", key: prefix + "key", kind: nvramString},
+		{attr: "static_key", key: prefix + "static", kind: nvramString},
+	}
+}
+
+func resourceOpenVPNClientCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	openVPNClientLock.Lock()
+	defer openVPNClientLock.Unlock()
+
+	client := d.Get("client").(int)
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	prefix := fmt.Sprintf("vpn_client%d_", client)
+
+	entries := make(map[string]string)
+	nvramFieldsWrite(d, openVPNClientFields(client), entries)
+
+	entries[prefix+"userauth"] = boolToNVRAM(d.Get("username").(string) != "")
+
+	for i, rgw := range openVPNClientRedirectGateway {
+		if rgw == d.Get("redirect_gateway").(string) {
+			entries[prefix+"rgw"] = strconv.Itoa(i)
+		}
+	}
+
+	entries[prefix+"routing_val"] = openVPNClientRenderRouting(d.Get("routing_policy").([]interface{}))
+
+	enabled := d.Get("enabled").(bool)
+	entries["vpn_client_eas"] = updateUnitList(n["vpn_client_eas"], client, enabled)
+
+	service := fmt.Sprintf("vpnclient%d-stop", client)
+	if enabled {
+		service = fmt.Sprintf("vpnclient%d-restart", client)
+	}
+
+	b, err := c.applyChange(service, encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.Itoa(client))
+
+	resourceOpenVPNClientRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceOpenVPNClientRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	client, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	prefix := fmt.Sprintf("vpn_client%d_", client)
+
+	if _, found := n[prefix+"if"]; !found {
+		d.SetId("")
+		return diags
+	}
+
+	if err := d.Set("client", client); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := nvramFieldsRead(d, n, openVPNClientFields(client)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("enabled", unitListContains(n["vpn_client_eas"], client)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	rgw, _ := strconv.Atoi(n[prefix+"rgw"])
+	if rgw >= 0 && rgw < len(openVPNClientRedirectGateway) {
+		if err := d.Set("redirect_gateway", openVPNClientRedirectGateway[rgw]); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if err := d.Set("routing_policy", openVPNClientParseRouting(n[prefix+"routing_val"])); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceOpenVPNClientUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := resourceOpenVPNClientCreate(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	return resourceOpenVPNClientRead(ctx, d, m)
+}
+
+func resourceOpenVPNClientDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	c := m.(*Client)
+
+	openVPNClientLock.Lock()
+	defer openVPNClientLock.Unlock()
+
+	client, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// stop the client and drop the credentials, the remaining settings are left as they are
+	prefix := fmt.Sprintf("vpn_client%d_", client)
+	entries := map[string]string{
+		"vpn_client_eas":       updateUnitList(n["vpn_client_eas"], client, false),
+		prefix + "password":    "",
+		prefix + "key":         "",
+		prefix + "static":      "",
+		prefix + "routing_val": "",
+	}
+
+	b, err := c.applyChange(fmt.Sprintf("vpnclient%d-stop", client), encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+// vpn_clientN_routing_val holds enabled<type<value> records
+func openVPNClientRenderRouting(rules []interface{}) string {
+	entries := ""
+	for _, r := range rules {
+		rule := r.(map[string]interface{})
+		kind := 0
+		for i, t := range openVPNClientRoutingTypes {
+			if t == rule["type"].(string) {
+				kind = i
+			}
+		}
+		entries += fmt.Sprintf("%s<%d<%s>", boolToNVRAM(rule["enabled"].(bool)), kind, rule["value"].(string))
+	}
+	return entries
+}
+
+func openVPNClientParseRouting(routing_val string) []interface{} {
+	const (
+		enabled = 0
+		kind    = 1
+		value   = 2
+	)
+
+	rules := []interface{}{}
+	for _, record := range strings.Split(routing_val, ">") {
+		fields := strings.Split(record, "<")
+		if len(fields) < 3 {
+			continue
+		}
+		k, err := strconv.Atoi(fields[kind])
+		if err != nil || k < 1 || k >= len(openVPNClientRoutingTypes) {
+			continue
+		}
+		rules = append(rules, map[string]interface{}{
+			"enabled": fields[enabled] == "1",
+			"type":    openVPNClientRoutingTypes[k],
+			"value":   fields[value],
+		})
+	}
+	return rules
+}
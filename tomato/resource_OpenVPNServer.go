@@ -0,0 +1,413 @@
+package tomato
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var openVPNServerLock = &sync.Mutex{}
+
+func resourceOpenVPNServer() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceOpenVPNServerCreate,
+		ReadContext:   resourceOpenVPNServerRead,
+		UpdateContext: resourceOpenVPNServerUpdate,
+		DeleteContext: resourceOpenVPNServerDelete,
+		Schema: map[string]*schema.Schema{
+			"server": &schema.Schema{
+				Type:         schema.TypeInt,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntBetween(1, 2),
+			},
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"interface_type": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "tun",
+				ValidateFunc: validation.StringInSlice([]string{"tun", "tap"}, false),
+			},
+			"protocol": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "udp",
+				ValidateFunc: validation.StringInSlice([]string{"udp", "tcp-server", "udp4", "tcp4-server", "udp6", "tcp6-server"}, false),
+			},
+			"port": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1194,
+				ValidateFunc: validation.IsPortNumber,
+			},
+			"cipher": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"crypt": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "tls",
+				ValidateFunc: validation.StringInSlice([]string{"tls", "secret", "custom"}, false),
+			},
+			"subnet": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "10.8.0.0/24",
+				ValidateFunc: validation.IsCIDRNetwork(0, 32),
+			},
+			"push_lan": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"redirect_gateway": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"push_routes": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.IsCIDRNetwork(0, 32),
+				},
+			},
+			"client_to_client": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"ccd_exclusive": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"ccd": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"common_name": &schema.Schema{
+							Type:     schema.TypeString,
+							Required: true,
+						},
+						"subnet": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.IsCIDRNetwork(0, 32),
+						},
+						"push": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  false,
+						},
+						"enabled": &schema.Schema{
+							Type:     schema.TypeBool,
+							Optional: true,
+							Default:  true,
+						},
+					},
+				},
+			},
+			"custom": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"ca": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"cert": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"key": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"dh": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+			"static_key": &schema.Schema{
+				Type:      schema.TypeString,
+				Optional:  true,
+				Sensitive: true,
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func openVPNServerFields(server int) []nvramField {
+	prefix := fmt.Sprintf("vpn_server%d_", server)
+	return []nvramField{
+		{attr: "interface_type", key: prefix + "if", kind: nvramString},
+		{attr: "protocol", key: prefix + "proto", kind: nvramString},
+		{attr: "port", key: prefix + "port", kind: nvramInt},
+		{attr: "cipher", key: prefix + "cipher", kind: nvramString, omitEmpty: true},
+		{attr: "crypt", key: prefix + "crypt", kind: nvramString},
+		{attr: "push_lan", key: prefix + "plan", kind: nvramBool},
+		{attr: "redirect_gateway", key: prefix + "rgw", kind: nvramBool},
+		{attr: "client_to_client", key: prefix + "c2c", kind: nvramBool},
+		{attr: "ccd_exclusive", key: prefix + "ccd_excl", kind: nvramBool},
+		{attr: "ca", key: prefix + "ca", kind: nvramString},
+		{attr: "cert", key: prefix + "crt", kind: nvramString},
+		{attr: "key", key: prefix + "key", kind: nvramString},
+		{attr: "dh", key: prefix + "dh", kind: nvramString},
+		{attr: "static_key", key: prefix + "static", kind: nvramString},
+	}
+}
+
+func resourceOpenVPNServerCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	openVPNServerLock.Lock()
+	defer openVPNServerLock.Unlock()
+
+	server := d.Get("server").(int)
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	entries := make(map[string]string)
+	nvramFieldsWrite(d, openVPNServerFields(server), entries)
+
+	prefix := fmt.Sprintf("vpn_server%d_", server)
+
+	subnet, netmask, err := cidrToAddrMask(d.Get("subnet").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	entries[prefix+"sn"] = subnet
+	entries[prefix+"nm"] = netmask
+
+	custom, err := openVPNServerRenderCustom(d.Get("push_routes").([]interface{}), d.Get("custom").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	entries[prefix+"custom"] = custom
+
+	ccd, err := openVPNServerRenderCCD(d.Get("ccd").([]interface{}))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	entries[prefix+"ccd"] = boolToNVRAM(ccd != "")
+	entries[prefix+"ccd_val"] = ccd
+
+	enabled := d.Get("enabled").(bool)
+	entries["vpn_server_eas"] = updateUnitList(n["vpn_server_eas"], server, enabled)
+
+	service := fmt.Sprintf("vpnserver%d-stop", server)
+	if enabled {
+		service = fmt.Sprintf("vpnserver%d-restart", server)
+	}
+
+	b, err := c.applyChange(service, encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.Itoa(server))
+
+	resourceOpenVPNServerRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceOpenVPNServerRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	server, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	prefix := fmt.Sprintf("vpn_server%d_", server)
+
+	if _, found := n[prefix+"if"]; !found {
+		d.SetId("")
+		return diags
+	}
+
+	if err := d.Set("server", server); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := nvramFieldsRead(d, n, openVPNServerFields(server)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("enabled", unitListContains(n["vpn_server_eas"], server)); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("subnet", addrMaskToCIDR(n[prefix+"sn"], n[prefix+"nm"])); err != nil {
+		return diag.FromErr(err)
+	}
+
+	routes, custom := openVPNServerParseCustom(n[prefix+"custom"])
+	if err := d.Set("push_routes", routes); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("custom", custom); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("ccd", openVPNServerParseCCD(n[prefix+"ccd_val"])); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceOpenVPNServerUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := resourceOpenVPNServerCreate(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	return resourceOpenVPNServerRead(ctx, d, m)
+}
+
+func resourceOpenVPNServerDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	c := m.(*Client)
+
+	openVPNServerLock.Lock()
+	defer openVPNServerLock.Unlock()
+
+	server, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// stop the server and drop the key material, the remaining settings are left as they are
+	prefix := fmt.Sprintf("vpn_server%d_", server)
+	entries := map[string]string{
+		"vpn_server_eas":  updateUnitList(n["vpn_server_eas"], server, false),
+		prefix + "ca":     "",
+		prefix + "crt":    "",
+		prefix + "key":    "",
+		prefix + "dh":     "",
+		prefix + "static": "",
+	}
+
+	b, err := c.applyChange(fmt.Sprintf("vpnserver%d-stop", server), encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+var openVPNPushRouteRe = regexp.MustCompile(`^push "route ([0-9.]+) ([0-9.]+)"$`)
+
+// push routes are kept as push directives at the top of vpn_serverN_custom
+func openVPNServerRenderCustom(routes []interface{}, custom string) (string, error) {
+	lines := []string{}
+	for _, r := range routes {
+		addr, mask, err := cidrToAddrMask(r.(string))
+		if err != nil {
+			return "", err
+		}
+		lines = append(lines, fmt.Sprintf(`push "route %s %s"`, addr, mask))
+	}
+	if custom != "" {
+		lines = append(lines, custom)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func openVPNServerParseCustom(custom string) ([]string, string) {
+	routes := []string{}
+	rest := []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(custom, "\r\n", "\n"), "\n") {
+		if match := openVPNPushRouteRe.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			routes = append(routes, addrMaskToCIDR(match[1], match[2]))
+			continue
+		}
+		rest = append(rest, line)
+	}
+	return routes, strings.Join(rest, "\n")
+}
+
+// vpn_serverN_ccd_val holds enabled<common name<subnet<netmask<push> records
+func openVPNServerRenderCCD(ccd []interface{}) (string, error) {
+	entries := ""
+	for _, e := range ccd {
+		entry := e.(map[string]interface{})
+		subnet, netmask, err := cidrToAddrMask(entry["subnet"].(string))
+		if err != nil {
+			return "", err
+		}
+		entries += fmt.Sprintf("%s<%s<%s<%s<%s>", boolToNVRAM(entry["enabled"].(bool)), entry["common_name"].(string), subnet, netmask, boolToNVRAM(entry["push"].(bool)))
+	}
+	return entries, nil
+}
+
+func openVPNServerParseCCD(ccd_val string) []interface{} {
+	const (
+		enabled    = 0
+		commonName = 1
+		subnet     = 2
+		netmask    = 3
+		push       = 4
+	)
+
+	entries := []interface{}{}
+	for _, record := range strings.Split(ccd_val, ">") {
+		fields := strings.Split(record, "<")
+		if len(fields) < 5 {
+			continue
+		}
+		entries = append(entries, map[string]interface{}{
+			"enabled":     fields[enabled] == "1",
+			"common_name": fields[commonName],
+			"subnet":      addrMaskToCIDR(fields[subnet], fields[netmask]),
+			"push":        fields[push] == "1",
+		})
+	}
+	return entries
+}
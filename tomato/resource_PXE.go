@@ -0,0 +1,246 @@
+package tomato
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// the PXE settings are a managed block in dnsmasq_custom and share DNSEntryLock with tomato_dns_entry
+const pxeBlockName = "pxe"
+
+func resourcePXE() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourcePXECreate,
+		ReadContext:   resourcePXERead,
+		UpdateContext: resourcePXEUpdate,
+		DeleteContext: resourcePXEDelete,
+		Schema: map[string]*schema.Schema{
+			"tftp_root": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+			},
+			"bios_boot_file": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				AtLeastOneOf: []string{"bios_boot_file", "uefi_boot_file"},
+			},
+			"uefi_boot_file": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				AtLeastOneOf: []string{"bios_boot_file", "uefi_boot_file"},
+			},
+			"boot_server": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsIPv4Address,
+			},
+			"tag": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourcePXECreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	DNSEntryLock.Lock()
+	defer DNSEntryLock.Unlock()
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// there is only one PXE block, a new resource must not take over one it does not own
+	if entry, _ := findManagedBlock(pxeBlockName, n["dnsmasq_custom"]); entry != "" && d.Id() == "" {
+		return diag.FromErr(fmt.Errorf("block %s already exists in dnsmasq_custom, import it instead", pxeBlockName))
+	}
+
+	dnsmasq_custom := setManagedBlock(pxeBlockName, n["dnsmasq_custom"], pxeRender(d))
+
+	dnsconfig := url.QueryEscape(dnsmasq_custom)
+
+	tflog.Debug(ctx, "Apply dnsconfig:\n"+dnsconfig)
+
+	b, err := c.applyChange("dnsmasq-restart", "dnsmasq_custom="+dnsconfig)
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(pxeBlockName)
+
+	resourcePXERead(ctx, d, m)
+
+	return diags
+}
+
+func resourcePXERead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	entry, block := findManagedBlock(pxeBlockName, n["dnsmasq_custom"])
+	if len(entry) == 0 {
+		d.SetId("")
+		return diags
+	}
+
+	tftp_root, bios, uefi, server, tag := pxeParse(block)
+	if err := d.Set("tftp_root", tftp_root); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("bios_boot_file", bios); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("uefi_boot_file", uefi); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("boot_server", server); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("tag", tag); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourcePXEUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := resourcePXECreate(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	return resourcePXERead(ctx, d, m)
+}
+
+func resourcePXEDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	c := m.(*Client)
+
+	DNSEntryLock.Lock()
+	defer DNSEntryLock.Unlock()
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	dnsmasq_custom := n["dnsmasq_custom"]
+
+	entry, _ := findManagedBlock(pxeBlockName, dnsmasq_custom)
+	if len(entry) == 0 {
+		return diags
+	}
+
+	dnsmasq_custom = strings.Replace(dnsmasq_custom, entry, "", -1)
+
+	dnsconfig := url.QueryEscape(dnsmasq_custom)
+
+	tflog.Debug(ctx, "Apply dnsconfig:\n"+dnsconfig)
+
+	b, err := c.applyChange("dnsmasq-restart", "dnsmasq_custom="+dnsconfig)
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+// clients are told apart by the architecture in DHCP option 93, 0 is BIOS and 7 or 9 are x86-64 UEFI
+func pxeRender(d *schema.ResourceData) string {
+	tag := ""
+	if t := d.Get("tag").(string); t != "" {
+		tag = "tag:" + t + ","
+	}
+	server := ""
+	if s := d.Get("boot_server").(string); s != "" {
+		server = ",," + s
+	}
+
+	lines := []string{
+		"enable-tftp",
+		"tftp-root=" + d.Get("tftp_root").(string),
+	}
+	if bios := d.Get("bios_boot_file").(string); bios != "" {
+		lines = append(lines,
+			"dhcp-match=set:pxe-bios,option:client-arch,0",
+			fmt.Sprintf("dhcp-boot=tag:pxe-bios,%s%s%s", tag, bios, server))
+	}
+	if uefi := d.Get("uefi_boot_file").(string); uefi != "" {
+		lines = append(lines,
+			"dhcp-match=set:pxe-uefi,option:client-arch,7",
+			"dhcp-match=set:pxe-uefi,option:client-arch,9",
+			fmt.Sprintf("dhcp-boot=tag:pxe-uefi,%s%s%s", tag, uefi, server))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func pxeParse(block string) (string, string, string, string, string) {
+	tftp_root, bios, uefi, server, tag := "", "", "", "", ""
+
+	for _, line := range strings.Split(block, "\n") {
+		if strings.HasPrefix(line, "tftp-root=") {
+			tftp_root = strings.TrimPrefix(line, "tftp-root=")
+			continue
+		}
+		if !strings.HasPrefix(line, "dhcp-boot=") {
+			continue
+		}
+
+		// dhcp-boot=tag:pxe-<arch>[,tag:<tag>],<file>[,,<server>]
+		fields := strings.Split(strings.TrimPrefix(line, "dhcp-boot="), ",")
+		if len(fields) < 2 {
+			continue
+		}
+		arch := fields[0]
+		fields = fields[1:]
+		if strings.HasPrefix(fields[0], "tag:") {
+			tag = strings.TrimPrefix(fields[0], "tag:")
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) == 3 {
+			server = fields[2]
+		}
+
+		switch arch {
+		case "tag:pxe-bios":
+			bios = fields[0]
+		case "tag:pxe-uefi":
+			uefi = fields[0]
+		}
+	}
+
+	return tftp_root, bios, uefi, server, tag
+}
@@ -0,0 +1,165 @@
+package tomato
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"golang.org/x/crypto/ssh"
+)
+
+var sshLock = &sync.Mutex{}
+
+func resourceSSH() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceSSHCreate,
+		ReadContext:   resourceSSHRead,
+		UpdateContext: resourceSSHUpdate,
+		DeleteContext: resourceSSHDelete,
+		Schema: map[string]*schema.Schema{
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"port": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      22,
+				ValidateFunc: validation.IsPortNumber,
+			},
+			"password_login": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"forwarding": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"remote_forwarding": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+			"authorized_keys": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validateAuthorizedKey,
+				},
+				// keys read with file() end in a newline, Read stores them trimmed
+				Set: func(v interface{}) int {
+					return schema.HashString(strings.TrimSpace(v.(string)))
+				},
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+var sshFields = []nvramField{
+	{attr: "enabled", key: "sshd_eas", kind: nvramBool},
+	{attr: "port", key: "sshd_port", kind: nvramInt},
+	{attr: "password_login", key: "sshd_pass", kind: nvramBool},
+	{attr: "forwarding", key: "sshd_forwarding", kind: nvramBool},
+	{attr: "remote_forwarding", key: "sshd_rwb", kind: nvramBool},
+}
+
+func resourceSSHCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	sshLock.Lock()
+	defer sshLock.Unlock()
+
+	entries := make(map[string]string)
+	nvramFieldsWrite(d, sshFields, entries)
+
+	keys := []string{}
+	for _, k := range d.Get("authorized_keys").(*schema.Set).List() {
+		keys = append(keys, strings.TrimSpace(k.(string)))
+	}
+	entries["sshd_authkeys"] = strings.Join(keys, "\n")
+
+	service := "sshd-stop"
+	if d.Get("enabled").(bool) {
+		service = "sshd-restart"
+	}
+
+	b, err := c.applyChange(service, encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("ssh")
+
+	resourceSSHRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceSSHRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := nvramFieldsRead(d, n, sshFields); err != nil {
+		return diag.FromErr(err)
+	}
+
+	keys := []string{}
+	for _, line := range strings.Split(n["sshd_authkeys"], "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, line)
+	}
+	if err := d.Set("authorized_keys", keys); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("ssh")
+	return diags
+}
+
+func resourceSSHUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := resourceSSHCreate(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	return resourceSSHRead(ctx, d, m)
+}
+
+func resourceSSHDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// the daemon and its keys are left as they are so the router stays reachable
+	var diags diag.Diagnostics
+	return diags
+}
+
+func validateAuthorizedKey(i interface{}, k string) ([]string, []error) {
+	if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(i.(string))); err != nil {
+		return nil, []error{fmt.Errorf("%s is not a valid OpenSSH public key: %s", k, err)}
+	}
+	return nil, nil
+}
@@ -0,0 +1,295 @@
+package tomato
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var scheduleLock = &sync.Mutex{}
+
+// bit n of the days mask is set for weekday n, starting on sunday
+var scheduleWeekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// intervals the scheduler accepts, stored as negative minutes
+var scheduleIntervals = []int{1, 2, 5, 10, 15, 20, 30, 60, 120, 180, 240, 360, 480, 720}
+
+const scheduleCustomSlots = 5
+
+func resourceSchedule() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceScheduleCreate,
+		ReadContext:   resourceScheduleRead,
+		UpdateContext: resourceScheduleUpdate,
+		DeleteContext: resourceScheduleDelete,
+		CustomizeDiff: resourceScheduleCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"job": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice([]string{"reboot", "reconnect", "custom"}, false),
+			},
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"time": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ExactlyOneOf: []string{"time", "every_minutes"},
+				ValidateFunc: validation.StringMatch(regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`), "must be HH:MM"),
+			},
+			"every_minutes": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				ExactlyOneOf: []string{"time", "every_minutes"},
+				ValidateFunc: validation.IntInSlice(scheduleIntervals),
+			},
+			"days": &schema.Schema{
+				Type:     schema.TypeSet,
+				Optional: true,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringInSlice(scheduleWeekdays, false),
+				},
+			},
+			"command": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"slot": &schema.Schema{
+				Type:     schema.TypeInt,
+				Computed: true,
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceScheduleCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	job := d.Get("job").(string)
+	command := d.Get("command").(string)
+
+	if job == "custom" && command == "" {
+		return errors.New("command is required for custom jobs")
+	}
+	if job != "custom" && command != "" {
+		return fmt.Errorf("command can only be set for custom jobs, not %s", job)
+	}
+	return nil
+}
+
+func resourceScheduleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	scheduleLock.Lock()
+	defer scheduleLock.Unlock()
+
+	id := d.Id()
+	if id == "" {
+		switch job := d.Get("job").(string); job {
+		case "reboot":
+			id = "rboot"
+		case "reconnect":
+			id = "rcon"
+		default:
+			n, err := c.getNVRAM()
+			if err != nil {
+				return diag.FromErr(err)
+			}
+			id = scheduleFreeSlot(n)
+			if id == "" {
+				return diag.FromErr(errors.New("all custom scheduler slots are in use"))
+			}
+		}
+	}
+
+	entries := map[string]string{
+		"sch_" + id: scheduleRender(d),
+	}
+	if strings.HasPrefix(id, "c") {
+		entries["sch_"+id+"_cmd"] = d.Get("command").(string)
+	}
+
+	b, err := c.applyChange("sched-restart", encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+
+	resourceScheduleRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceScheduleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	id := d.Id()
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	value, found := n["sch_"+id]
+	if !found {
+		d.SetId("")
+		return diags
+	}
+
+	// enabled,time,days
+	fields := strings.Split(value, ",")
+	if len(fields) != 3 {
+		return diag.FromErr(fmt.Errorf("unexpected value %q in sch_%s", value, id))
+	}
+	minutes, _ := strconv.Atoi(fields[1])
+	mask, _ := strconv.Atoi(fields[2])
+
+	job, slot := "custom", 0
+	switch id {
+	case "rboot":
+		job = "reboot"
+	case "rcon":
+		job = "reconnect"
+	default:
+		slot, _ = strconv.Atoi(strings.TrimPrefix(id, "c"))
+	}
+
+	days := []string{}
+	for i, day := range scheduleWeekdays {
+		if mask&(1<<i) != 0 {
+			days = append(days, day)
+		}
+	}
+
+	if err := d.Set("job", job); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("slot", slot); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("enabled", fields[0] == "1"); err != nil {
+		return diag.FromErr(err)
+	}
+	if minutes < 0 {
+		if err := d.Set("every_minutes", -minutes); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("time", ""); err != nil {
+			return diag.FromErr(err)
+		}
+	} else {
+		if err := d.Set("time", fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)); err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("every_minutes", 0); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+	if err := d.Set("days", days); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("command", n["sch_"+id+"_cmd"]); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceScheduleUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := resourceScheduleCreate(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	return resourceScheduleRead(ctx, d, m)
+}
+
+func resourceScheduleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	c := m.(*Client)
+
+	scheduleLock.Lock()
+	defer scheduleLock.Unlock()
+
+	id := d.Id()
+
+	// disable the job but keep its time so the GUI shows the last setting
+	fields := strings.Split(scheduleRender(d), ",")
+	fields[0] = "0"
+
+	entries := map[string]string{
+		"sch_" + id: strings.Join(fields, ","),
+	}
+	if strings.HasPrefix(id, "c") {
+		entries["sch_"+id+"_cmd"] = ""
+	}
+
+	b, err := c.applyChange("sched-restart", encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+// render enabled,time,days where time is minutes past midnight or minus the interval
+func scheduleRender(d *schema.ResourceData) string {
+	minutes := -d.Get("every_minutes").(int)
+	if t := d.Get("time").(string); t != "" {
+		hm := strings.Split(t, ":")
+		h, _ := strconv.Atoi(hm[0])
+		m, _ := strconv.Atoi(hm[1])
+		minutes = h*60 + m
+	}
+
+	mask := 0
+	days := d.Get("days").(*schema.Set)
+	for i, day := range scheduleWeekdays {
+		if days.Len() == 0 || days.Contains(day) {
+			mask |= 1 << i
+		}
+	}
+
+	return fmt.Sprintf("%s,%d,%d", boolToNVRAM(d.Get("enabled").(bool)), minutes, mask)
+}
+
+// a custom slot is free when it is disabled and has no command
+func scheduleFreeSlot(n map[string]string) string {
+	for i := 1; i <= scheduleCustomSlots; i++ {
+		id := fmt.Sprintf("c%d", i)
+		if strings.HasPrefix(n["sch_"+id], "1,") || n["sch_"+id+"_cmd"] != "" {
+			continue
+		}
+		return id
+	}
+	return ""
+}
@@ -0,0 +1,275 @@
+package tomato
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var scriptLock = &sync.Mutex{}
+
+// NVRAM key and service to restart for each hook
+var scriptHooks = map[string][2]string{
+	"init":       {"script_init", ""},
+	"firewall":   {"script_fire", "firewall-restart"},
+	"wanup":      {"script_wanup", ""},
+	"shutdown":   {"script_shut", ""},
+	"mountpoint": {"script_mountpoint", ""},
+}
+
+func resourceScript() *schema.Resource {
+	hooks := []string{}
+	for hook := range scriptHooks {
+		hooks = append(hooks, hook)
+	}
+	sort.Strings(hooks)
+
+	return &schema.Resource{
+		CreateContext: resourceScriptCreate,
+		ReadContext:   resourceScriptRead,
+		UpdateContext: resourceScriptUpdate,
+		DeleteContext: resourceScriptDelete,
+		Schema: map[string]*schema.Schema{
+			"hook": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringInSlice(hooks, false),
+			},
+			"name": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.StringMatch(regexp.MustCompile(`^[a-zA-Z0-9_.-]+$`), "must only contain letters, digits, _, . and -"),
+			},
+			"content": &schema.Schema{
+				Type:     schema.TypeString,
+				Required: true,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return strings.TrimRight(old, "\n") == strings.TrimRight(new, "\n")
+				},
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceScriptCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	scriptLock.Lock()
+	defer scriptLock.Unlock()
+
+	hook := d.Get("hook").(string)
+	name := d.Get("name").(string)
+	content := strings.TrimRight(d.Get("content").(string), "\n")
+
+	key, service := scriptHooks[hook][0], scriptHooks[hook][1]
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	script := scriptKeepFragments(content, n[key])
+	id := hook
+
+	// a named fragment is appended to whatever else the script holds
+	if name != "" {
+		script = n[key]
+		if eentry, _ := findManagedBlock(name, script); eentry != "" {
+			return diag.FromErr(fmt.Errorf("fragment %s already exists in %s", name, key))
+		}
+		if script != "" && !strings.HasSuffix(script, "\n") {
+			script += "\n"
+		}
+		script += renderManagedBlock(name, content)
+		id = hook + ":" + name
+	}
+
+	tflog.Debug(ctx, "Apply script:\n"+script)
+
+	b, err := c.applyChange(service, encodeNVRAM(map[string]string{key: script}))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(id)
+
+	resourceScriptRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceScriptRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	hook, name, err := scriptParseID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	script, found := n[scriptHooks[hook][0]]
+	rest, _ := splitManagedBlocks(script)
+	content := strings.TrimRight(rest, "\n")
+	if name != "" {
+		found = false
+		if entry, fragment := findManagedBlock(name, script); entry != "" {
+			found = true
+			content = fragment
+		}
+	}
+
+	if !found {
+		d.SetId("")
+		return diags
+	}
+
+	if err := d.Set("hook", hook); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("name", name); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("content", content); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceScriptUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+
+	c := m.(*Client)
+
+	scriptLock.Lock()
+	defer scriptLock.Unlock()
+
+	hook, name, err := scriptParseID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	key, service := scriptHooks[hook][0], scriptHooks[hook][1]
+	content := strings.TrimRight(d.Get("content").(string), "\n")
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	script := scriptKeepFragments(content, n[key])
+	if name != "" {
+		script = n[key]
+		eentry, _ := findManagedBlock(name, script)
+		if eentry == "" {
+			return diag.FromErr(errors.New("ID Not Found"))
+		}
+		script = strings.Replace(script, eentry, renderManagedBlock(name, content), -1)
+	}
+
+	tflog.Debug(ctx, "Apply script:\n"+script)
+
+	b, err := c.applyChange(service, encodeNVRAM(map[string]string{key: script}))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceScriptRead(ctx, d, m)
+}
+
+func resourceScriptDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	c := m.(*Client)
+
+	scriptLock.Lock()
+	defer scriptLock.Unlock()
+
+	hook, name, err := scriptParseID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	key, service := scriptHooks[hook][0], scriptHooks[hook][1]
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// removing the whole script leaves the fragments other resources own
+	_, script := splitManagedBlocks(n[key])
+	if name != "" {
+		script = n[key]
+		entry, _ := findManagedBlock(name, script)
+		if len(entry) == 0 {
+			return diags
+		}
+		script = strings.Replace(script, entry, "", -1)
+	}
+
+	tflog.Debug(ctx, "Apply script:\n"+script)
+
+	b, err := c.applyChange(service, encodeNVRAM(map[string]string{key: script}))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+// the ID is <hook> for a whole script or <hook>:<name> for a fragment
+func scriptParseID(id string) (string, string, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if _, ok := scriptHooks[parts[0]]; !ok {
+		return "", "", fmt.Errorf("invalid ID %q, expected <hook> or <hook>:<name>", id)
+	}
+	if len(parts) == 1 {
+		return parts[0], "", nil
+	}
+	return parts[0], parts[1], nil
+}
+
+// a whole script only owns the lines outside the named fragments, those are kept after it
+func scriptKeepFragments(content, current string) string {
+	_, fragments := splitManagedBlocks(current)
+	if fragments == "" {
+		return content
+	}
+	if content == "" {
+		return fragments
+	}
+	return content + "\n" + fragments
+}
@@ -4,14 +4,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
 
 var staticIpLock = &sync.Mutex{}
@@ -22,6 +25,7 @@ func resourceStaticIp() *schema.Resource {
 		ReadContext:   resourceStaticIpRead,
 		UpdateContext: resourceStaticIpUpdate,
 		DeleteContext: resourceStaticIpDelete,
+		CustomizeDiff: resourceStaticIpCustomizeDiff,
 		Schema: map[string]*schema.Schema{
 			"mac": &schema.Schema{
 				Type:     schema.TypeString,
@@ -39,11 +43,32 @@ func resourceStaticIp() *schema.Resource {
 				Type:     schema.TypeString,
 				Required: true,
 			},
+			"bridge": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Computed:     true,
+				ValidateFunc: validation.IntBetween(0, 3),
+			},
 			"bind": &schema.Schema{
 				Type:     schema.TypeBool,
 				Optional: true,
 				Default:  false,
 			},
+			"dhcp_options": &schema.Schema{
+				Type:     schema.TypeMap,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"tags": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.StringMatch(regexp.MustCompile(`^[a-zA-Z0-9_-]+$`), "must only contain letters, digits, _ and -"),
+				},
+			},
 		},
 		Importer: &schema.ResourceImporter{
 			StateContext: schema.ImportStatePassthroughContext,
@@ -58,6 +83,8 @@ func resourceStaticIpCreate(ctx context.Context, d *schema.ResourceData, m inter
 
 	staticIpLock.Lock()
 	defer staticIpLock.Unlock()
+	DNSEntryLock.Lock()
+	defer DNSEntryLock.Unlock()
 
 	n, err := c.getNVRAM()
 	if err != nil {
@@ -95,6 +122,8 @@ func resourceStaticIpCreate(ctx context.Context, d *schema.ResourceData, m inter
 
 	tflog.Debug(ctx, "Apply dhcpconfig:\n"+dhcpconfig)
 
+	dhcpconfig += staticIpDnsmasqChange(n["dnsmasq_custom"], "", mac, staticIpRenderOptions(d))
+
 	b, err := c.applyChange("dhcpd-restart%2Carpbind-restart%2Ccstats-restart%2Cdnsmasq-restart", "dhcpd_static="+dhcpconfig)
 
 	tflog.Debug(ctx, b)
@@ -139,6 +168,11 @@ func resourceStaticIpRead(ctx context.Context, d *schema.ResourceData, m interfa
 	if err := d.Set("hostname", hostname); err != nil {
 		return diag.FromErr(err)
 	}
+	if bridge, found := staticIpBridge(ip, n); found {
+		if err := d.Set("bridge", bridge); err != nil {
+			return diag.FromErr(err)
+		}
+	}
 	if options == "0" {
 		if err := d.Set("bind", false); err != nil {
 			return diag.FromErr(err)
@@ -149,6 +183,15 @@ func resourceStaticIpRead(ctx context.Context, d *schema.ResourceData, m interfa
 		}
 	}
 
+	_, block := findManagedBlock(staticIpBlockName(emac), n["dnsmasq_custom"])
+	tags, dhcp_options := staticIpParseOptions(emac, block)
+	if err := d.Set("tags", tags); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("dhcp_options", dhcp_options); err != nil {
+		return diag.FromErr(err)
+	}
+
 	if len(emac) == 0 {
 		d.SetId("")
 	} else {
@@ -157,31 +200,92 @@ func resourceStaticIpRead(ctx context.Context, d *schema.ResourceData, m interfa
 	return diags
 }
 
-func staticIpFindEntry(smac, dhcp_static string) (string, string, string, string, string, string) {
+// submatch indexes of a dhcpd_static entry
+const (
+	staticIpMAC      = 1
+	staticIpMAC2     = 3
+	staticIpIP       = 4
+	staticIpHostname = 5
+	staticIpBind     = 6
+)
+
+func staticIpEntries(dhcp_static string) [][]string {
 	var re = regexp.MustCompile(`((?:[[:alnum:]]{2}:){5}[[:alnum:]]{2})(,((?:[[:alnum:]]{2}:){5}[[:alnum:]]{2}))?<([0-9]+\.[0-9]+\.[0-9]+\.[0-9]+)<([^<]+)<([0-2])>`)
-	const (
-		mac      = 1
-		mac2     = 3
-		ip       = 4
-		hostname = 5
-		bind     = 6
-	)
-
-	matches := re.FindAllStringSubmatch(dhcp_static, -1)
+
+	return re.FindAllStringSubmatch(dhcp_static, -1)
+}
+
+func staticIpFindEntry(smac, dhcp_static string) (string, string, string, string, string, string) {
+	matches := staticIpEntries(dhcp_static)
 	for i := range matches {
-		if matches[i][mac] == smac {
-			return matches[i][0], matches[i][mac], matches[i][mac2], matches[i][ip], matches[i][hostname], matches[i][bind]
+		if matches[i][staticIpMAC] == smac {
+			return matches[i][0], matches[i][staticIpMAC], matches[i][staticIpMAC2], matches[i][staticIpIP], matches[i][staticIpHostname], matches[i][staticIpBind]
 		}
 	}
 	return "", "", "", "", "", ""
 }
 
+// the bridge whose subnet holds ip, reservations outside every LAN bridge are never handed out
+func staticIpBridge(ip string, n map[string]string) (int, bool) {
+	for bridge, subnet := range bridgeSubnets(n) {
+		if subnet.Contains(net.ParseIP(ip)) {
+			return bridge, true
+		}
+	}
+	return 0, false
+}
+
+// the address must sit inside the subnet of the bridge the reservation is for,
+// when bridge is not set it is the one whose subnet holds the address
+func resourceStaticIpCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	configured := d.GetRawConfig().GetAttr("bridge")
+	if !d.NewValueKnown("ip") || !configured.IsKnown() {
+		return nil
+	}
+
+	c, ok := m.(*Client)
+	if !ok || c.HostURL == "" {
+		return nil
+	}
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return err
+	}
+
+	subnets := bridgeSubnets(n)
+	if len(subnets) == 0 {
+		return nil
+	}
+
+	ip := d.Get("ip").(string)
+	if configured.IsNull() {
+		bridge, found := staticIpBridge(ip, n)
+		if !found {
+			return fmt.Errorf("%s is not inside the subnet of any LAN bridge", ip)
+		}
+		return d.SetNew("bridge", bridge)
+	}
+
+	bridge := d.Get("bridge").(int)
+	subnet, ok := subnets[bridge]
+	if !ok {
+		return fmt.Errorf("br%d has no LAN address configured", bridge)
+	}
+	if !subnet.Contains(net.ParseIP(ip)) {
+		return fmt.Errorf("%s is outside the br%d subnet %s", ip, bridge, subnet)
+	}
+	return nil
+}
+
 func resourceStaticIpUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 
 	c := m.(*Client)
 
 	staticIpLock.Lock()
 	defer staticIpLock.Unlock()
+	DNSEntryLock.Lock()
+	defer DNSEntryLock.Unlock()
 
 	ip := d.Get("ip").(string)
 	mac := d.Get("mac").(string)
@@ -225,6 +329,8 @@ func resourceStaticIpUpdate(ctx context.Context, d *schema.ResourceData, m inter
 
 	tflog.Debug(ctx, "Apply dnsconfig:\n"+dhcpd_static)
 
+	dhcpconfig += staticIpDnsmasqChange(n["dnsmasq_custom"], d.Id(), mac, staticIpRenderOptions(d))
+
 	b, err := c.applyChange("dhcpd-restart%2Carpbind-restart%2Ccstats-restart%2Cdnsmasq-restart", "dhcpd_static="+dhcpconfig)
 
 	tflog.Debug(ctx, b)
@@ -245,6 +351,8 @@ func resourceStaticIpDelete(ctx context.Context, d *schema.ResourceData, m inter
 
 	staticIpLock.Lock()
 	defer staticIpLock.Unlock()
+	DNSEntryLock.Lock()
+	defer DNSEntryLock.Unlock()
 
 	n, err := c.getNVRAM()
 	if err != nil {
@@ -268,6 +376,8 @@ func resourceStaticIpDelete(ctx context.Context, d *schema.ResourceData, m inter
 
 	tflog.Debug(ctx, "Apply dhcpconfig:\n"+dhcpconfig)
 
+	dhcpconfig += staticIpDnsmasqChange(n["dnsmasq_custom"], d.Id(), "", "")
+
 	b, err := c.applyChange("dhcpd-restart%2Carpbind-restart%2Ccstats-restart%2Cdnsmasq-restart", "dhcpd_static="+dhcpconfig)
 
 	tflog.Debug(ctx, b)
@@ -278,3 +388,79 @@ func resourceStaticIpDelete(ctx context.Context, d *schema.ResourceData, m inter
 
 	return diags
 }
+
+// per host options live in a dnsmasq_custom block named after the MAC
+func staticIpBlockName(mac string) string {
+	return "static-ip-" + strings.ToLower(mac)
+}
+
+// tag set on the host so its dhcp_options only apply to it
+func staticIpHostTag(mac string) string {
+	return "host-" + strings.ToLower(strings.ReplaceAll(mac, ":", ""))
+}
+
+// dhcp-mac is used rather than dhcp-host, dnsmasq only honours the first dhcp-host for a MAC and that one is
+// generated from dhcpd_static
+func staticIpRenderOptions(d *schema.ResourceData) string {
+	mac := d.Get("mac").(string)
+	tags := d.Get("tags").([]interface{})
+	dhcp_options := d.Get("dhcp_options").(map[string]interface{})
+
+	lines := []string{}
+	for _, tag := range tags {
+		lines = append(lines, fmt.Sprintf("dhcp-mac=set:%s,%s", tag.(string), mac))
+	}
+
+	if len(dhcp_options) > 0 {
+		host := staticIpHostTag(mac)
+		lines = append(lines, fmt.Sprintf("dhcp-mac=set:%s,%s", host, mac))
+
+		keys := make([]string, 0, len(dhcp_options))
+		for k := range dhcp_options {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			lines = append(lines, fmt.Sprintf("dhcp-option=tag:%s,%s,%s", host, k, dhcp_options[k].(string)))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func staticIpParseOptions(mac, block string) ([]string, map[string]string) {
+	host := staticIpHostTag(mac)
+
+	tags := []string{}
+	dhcp_options := make(map[string]string)
+	for _, line := range strings.Split(block, "\n") {
+		if strings.HasPrefix(line, "dhcp-mac=set:") {
+			tag := strings.SplitN(strings.TrimPrefix(line, "dhcp-mac=set:"), ",", 2)[0]
+			if tag != host {
+				tags = append(tags, tag)
+			}
+		} else if strings.HasPrefix(line, "dhcp-option=tag:"+host+",") {
+			kv := strings.SplitN(strings.TrimPrefix(line, "dhcp-option=tag:"+host+","), ",", 2)
+			if len(kv) == 2 {
+				dhcp_options[kv[0]] = kv[1]
+			}
+		}
+	}
+	return tags, dhcp_options
+}
+
+// the dnsmasq_custom part of the request, empty when the options of the host did not change
+func staticIpDnsmasqChange(dnsmasq_custom, omac, mac, options string) string {
+	custom := dnsmasq_custom
+	if omac != "" && omac != mac {
+		custom = setManagedBlock(staticIpBlockName(omac), custom, "")
+	}
+	if mac != "" {
+		custom = setManagedBlock(staticIpBlockName(mac), custom, options)
+	}
+
+	if custom == dnsmasq_custom {
+		return ""
+	}
+	return "&dnsmasq_custom=" + url.QueryEscape(custom)
+}
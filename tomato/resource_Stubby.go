@@ -0,0 +1,221 @@
+package tomato
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var stubbyLock = &sync.Mutex{}
+
+// values of stubby_priority
+var stubbyPriorities = []string{"none", "strict-order", "no-resolv"}
+
+func resourceStubby() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceStubbyCreate,
+		ReadContext:   resourceStubbyRead,
+		UpdateContext: resourceStubbyUpdate,
+		DeleteContext: resourceStubbyDelete,
+		Schema: map[string]*schema.Schema{
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"resolver": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				MinItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"address": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.IsIPAddress,
+						},
+						"port": &schema.Schema{
+							Type:         schema.TypeInt,
+							Optional:     true,
+							Default:      853,
+							ValidateFunc: validation.IsPortNumber,
+						},
+						"hostname": &schema.Schema{
+							Type:     schema.TypeString,
+							Optional: true,
+						},
+						"spki_pin": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							ValidateFunc: validateSPKIPin,
+						},
+					},
+				},
+			},
+			"priority": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "none",
+				ValidateFunc: validation.StringInSlice(stubbyPriorities, false),
+			},
+			"log_level": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      5,
+				ValidateFunc: validation.IntBetween(0, 7),
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceStubbyCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	stubbyLock.Lock()
+	defer stubbyLock.Unlock()
+
+	// stubby_resolvers holds <address>port>hostname>spki pin records
+	resolvers := ""
+	for _, r := range d.Get("resolver").([]interface{}) {
+		resolver := r.(map[string]interface{})
+		resolvers += fmt.Sprintf("<%s>%d>%s>%s", resolver["address"].(string), resolver["port"].(int), resolver["hostname"].(string), resolver["spki_pin"].(string))
+	}
+
+	priority := 0
+	for i, p := range stubbyPriorities {
+		if p == d.Get("priority").(string) {
+			priority = i
+		}
+	}
+
+	entries := map[string]string{
+		"stubby_proxy":     boolToNVRAM(d.Get("enabled").(bool)),
+		"stubby_resolvers": resolvers,
+		"stubby_priority":  strconv.Itoa(priority),
+		"stubby_log":       strconv.Itoa(d.Get("log_level").(int)),
+	}
+
+	b, err := c.applyChange(joinServices("stubby-restart", "dnsmasq-restart"), encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("stubby")
+
+	resourceStubbyRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceStubbyRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	const (
+		address  = 0
+		port     = 1
+		hostname = 2
+		spkiPin  = 3
+	)
+
+	resolvers := []interface{}{}
+	for _, record := range strings.Split(n["stubby_resolvers"], "<") {
+		fields := strings.Split(record, ">")
+		if len(fields) < 4 {
+			continue
+		}
+		p, err := strconv.Atoi(fields[port])
+		if err != nil {
+			p = 853
+		}
+		resolvers = append(resolvers, map[string]interface{}{
+			"address":  fields[address],
+			"port":     p,
+			"hostname": fields[hostname],
+			"spki_pin": fields[spkiPin],
+		})
+	}
+
+	priority, _ := strconv.Atoi(n["stubby_priority"])
+	if priority < 0 || priority >= len(stubbyPriorities) {
+		priority = 0
+	}
+	log_level, _ := strconv.Atoi(n["stubby_log"])
+
+	if err := d.Set("enabled", n["stubby_proxy"] == "1"); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("resolver", resolvers); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("priority", stubbyPriorities[priority]); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("log_level", log_level); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("stubby")
+	return diags
+}
+
+func resourceStubbyUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := resourceStubbyCreate(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	return resourceStubbyRead(ctx, d, m)
+}
+
+func resourceStubbyDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	c := m.(*Client)
+
+	stubbyLock.Lock()
+	defer stubbyLock.Unlock()
+
+	// fall back to plain DNS, the resolver list is kept for the GUI
+	b, err := c.applyChange(joinServices("stubby-restart", "dnsmasq-restart"), encodeNVRAM(map[string]string{"stubby_proxy": "0"}))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+// an SPKI pin is the base64 encoded SHA-256 digest of the server public key
+func validateSPKIPin(i interface{}, k string) ([]string, []error) {
+	pin, err := base64.StdEncoding.DecodeString(i.(string))
+	if err != nil || len(pin) != 32 {
+		return nil, []error{fmt.Errorf("%s is not a base64 encoded SHA-256 SPKI pin", k)}
+	}
+	return nil, nil
+}
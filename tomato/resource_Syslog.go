@@ -0,0 +1,158 @@
+package tomato
+
+import (
+	"context"
+	"strconv"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var syslogLock = &sync.Mutex{}
+
+// values of log_in and log_out
+var syslogConnectionLogging = []string{"none", "blocked", "allowed", "both"}
+
+func resourceSyslog() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceSyslogCreate,
+		ReadContext:   resourceSyslogRead,
+		UpdateContext: resourceSyslogUpdate,
+		DeleteContext: resourceSyslogDelete,
+		Schema: map[string]*schema.Schema{
+			"remote_host": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"remote_port": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      514,
+				ValidateFunc: validation.IsPortNumber,
+			},
+			"log_to_file": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"limit": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      60,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"inbound": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "none",
+				ValidateFunc: validation.StringInSlice(syslogConnectionLogging, false),
+			},
+			"outbound": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      "none",
+				ValidateFunc: validation.StringInSlice(syslogConnectionLogging, false),
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+var syslogFields = []nvramField{
+	{attr: "remote_host", key: "log_remoteip", kind: nvramString},
+	{attr: "remote_port", key: "log_remoteport", kind: nvramInt},
+	{attr: "log_to_file", key: "log_file", kind: nvramBool},
+	{attr: "limit", key: "log_limit", kind: nvramInt},
+}
+
+func resourceSyslogCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	syslogLock.Lock()
+	defer syslogLock.Unlock()
+
+	entries := make(map[string]string)
+	nvramFieldsWrite(d, syslogFields, entries)
+
+	entries["log_remote"] = boolToNVRAM(d.Get("remote_host").(string) != "")
+
+	for i, l := range syslogConnectionLogging {
+		if l == d.Get("inbound").(string) {
+			entries["log_in"] = strconv.Itoa(i)
+		}
+		if l == d.Get("outbound").(string) {
+			entries["log_out"] = strconv.Itoa(i)
+		}
+	}
+
+	b, err := c.applyChange("logger-restart", encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("syslog")
+
+	resourceSyslogRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceSyslogRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := nvramFieldsRead(d, n, syslogFields); err != nil {
+		return diag.FromErr(err)
+	}
+
+	// the remote address is kept by the GUI when remote logging is turned off
+	if n["log_remote"] != "1" {
+		if err := d.Set("remote_host", ""); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	for attr, key := range map[string]string{"inbound": "log_in", "outbound": "log_out"} {
+		i, _ := strconv.Atoi(n[key])
+		if i < 0 || i >= len(syslogConnectionLogging) {
+			i = 0
+		}
+		if err := d.Set(attr, syslogConnectionLogging[i]); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	d.SetId("syslog")
+	return diags
+}
+
+func resourceSyslogUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := resourceSyslogCreate(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	return resourceSyslogRead(ctx, d, m)
+}
+
+func resourceSyslogDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// logging is left as configured, the router always keeps a local log
+	var diags diag.Diagnostics
+	return diags
+}
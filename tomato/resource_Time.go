@@ -0,0 +1,131 @@
+package tomato
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+var timeLock = &sync.Mutex{}
+
+func resourceTime() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceTimeCreate,
+		ReadContext:   resourceTimeRead,
+		UpdateContext: resourceTimeUpdate,
+		DeleteContext: resourceTimeDelete,
+		Schema: map[string]*schema.Schema{
+			"timezone": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+				Computed: true,
+			},
+			"auto_dst": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"ntp_servers": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Computed: true,
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			"ntp_server_enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+var timeFields = []nvramField{
+	{attr: "timezone", key: "tm_tz", kind: nvramString, omitEmpty: true},
+	{attr: "auto_dst", key: "tm_dst", kind: nvramBool},
+	{attr: "ntp_server_enabled", key: "ntpd_enable", kind: nvramBool},
+}
+
+func resourceTimeCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	timeLock.Lock()
+	defer timeLock.Unlock()
+
+	entries := make(map[string]string)
+	nvramFieldsWrite(d, timeFields, entries)
+
+	// the GUI selection matches the zone unless it is set to custom
+	if tz, ok := entries["tm_tz"]; ok {
+		entries["tm_sel"] = tz
+	}
+
+	if servers, ok := d.GetOk("ntp_servers"); ok {
+		s := []string{}
+		for _, server := range servers.([]interface{}) {
+			s = append(s, server.(string))
+		}
+		entries["ntp_server"] = strings.Join(s, " ")
+	}
+
+	b, err := c.applyChange("ntpc-restart", encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("time")
+
+	resourceTimeRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceTimeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := nvramFieldsRead(d, n, timeFields); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("ntp_servers", strings.Fields(n["ntp_server"])); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("time")
+	return diags
+}
+
+func resourceTimeUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := resourceTimeCreate(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	return resourceTimeRead(ctx, d, m)
+}
+
+func resourceTimeDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// the clock keeps syncing with the last NTP servers, there is no default to go back to
+	var diags diag.Diagnostics
+	return diags
+}
@@ -0,0 +1,218 @@
+package tomato
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+var upnpLock = &sync.Mutex{}
+
+var upnpPortRangeRe = regexp.MustCompile(`^[0-9]+(-[0-9]+)?$`)
+
+// miniupnpd permission lines: allow|deny <external ports> <internal address> <internal ports>
+var upnpRuleRe = regexp.MustCompile(`^(allow|deny)\s+(\S+)\s+(\S+)\s+(\S+)$`)
+
+func resourceUPnP() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceUPnPCreate,
+		ReadContext:   resourceUPnPRead,
+		UpdateContext: resourceUPnPUpdate,
+		DeleteContext: resourceUPnPDelete,
+		Schema: map[string]*schema.Schema{
+			"upnp": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"natpmp": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"secure": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"clean": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"clean_interval": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      600,
+				ValidateFunc: validation.IntAtLeast(60),
+			},
+			"clean_threshold": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      20,
+				ValidateFunc: validation.IntAtLeast(0),
+			},
+			"rule": &schema.Schema{
+				Type:     schema.TypeList,
+				Optional: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"action": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.StringInSlice([]string{"allow", "deny"}, false),
+						},
+						"external_ports": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "0-65535",
+							ValidateFunc: validation.StringMatch(upnpPortRangeRe, "must be a port or a port range like 1024-65535"),
+						},
+						"internal_address": &schema.Schema{
+							Type:         schema.TypeString,
+							Required:     true,
+							ValidateFunc: validation.IsCIDR,
+						},
+						"internal_ports": &schema.Schema{
+							Type:         schema.TypeString,
+							Optional:     true,
+							Default:      "0-65535",
+							ValidateFunc: validation.StringMatch(upnpPortRangeRe, "must be a port or a port range like 1024-65535"),
+						},
+					},
+				},
+			},
+			"custom": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+var upnpFields = []nvramField{
+	{attr: "secure", key: "upnp_secure", kind: nvramBool},
+	{attr: "clean", key: "upnp_clean", kind: nvramBool},
+	{attr: "clean_interval", key: "upnp_clean_interval", kind: nvramInt},
+	{attr: "clean_threshold", key: "upnp_clean_threshold", kind: nvramInt},
+}
+
+func resourceUPnPCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	upnpLock.Lock()
+	defer upnpLock.Unlock()
+
+	entries := make(map[string]string)
+	nvramFieldsWrite(d, upnpFields, entries)
+
+	// upnp_enable is a bitmask, 1 for UPnP and 2 for NAT-PMP
+	enable := 0
+	if d.Get("upnp").(bool) {
+		enable |= 1
+	}
+	if d.Get("natpmp").(bool) {
+		enable |= 2
+	}
+	entries["upnp_enable"] = strconv.Itoa(enable)
+
+	// permission rules go first, miniupnpd applies the first one that matches
+	lines := []string{}
+	for _, r := range d.Get("rule").([]interface{}) {
+		rule := r.(map[string]interface{})
+		lines = append(lines, fmt.Sprintf("%s %s %s %s", rule["action"].(string), rule["external_ports"].(string), rule["internal_address"].(string), rule["internal_ports"].(string)))
+	}
+	if custom := d.Get("custom").(string); custom != "" {
+		lines = append(lines, custom)
+	}
+	entries["upnp_custom"] = strings.Join(lines, "\n")
+
+	b, err := c.applyChange("upnp-restart", encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("upnp")
+
+	resourceUPnPRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceUPnPRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if err := nvramFieldsRead(d, n, upnpFields); err != nil {
+		return diag.FromErr(err)
+	}
+
+	enable, _ := strconv.Atoi(n["upnp_enable"])
+	if err := d.Set("upnp", enable&1 != 0); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("natpmp", enable&2 != 0); err != nil {
+		return diag.FromErr(err)
+	}
+
+	rules := []interface{}{}
+	custom := []string{}
+	for _, line := range strings.Split(strings.ReplaceAll(n["upnp_custom"], "\r\n", "\n"), "\n") {
+		if match := upnpRuleRe.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			rules = append(rules, map[string]interface{}{
+				"action":           match[1],
+				"external_ports":   match[2],
+				"internal_address": match[3],
+				"internal_ports":   match[4],
+			})
+			continue
+		}
+		custom = append(custom, line)
+	}
+	if err := d.Set("rule", rules); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("custom", strings.Join(custom, "\n")); err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId("upnp")
+	return diags
+}
+
+func resourceUPnPUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := resourceUPnPCreate(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	return resourceUPnPRead(ctx, d, m)
+}
+
+func resourceUPnPDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// the UPnP settings and rules are left as they are so existing port mappings keep working
+	var diags diag.Diagnostics
+	return diags
+}
@@ -0,0 +1,264 @@
+package tomato
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"golang.org/x/crypto/curve25519"
+)
+
+// shared by tomato_wireguard_interface and tomato_wireguard_peer since both write wgN_* keys
+var wireguardLock = &sync.Mutex{}
+
+func resourceWireGuardInterface() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceWireGuardInterfaceCreate,
+		ReadContext:   resourceWireGuardInterfaceRead,
+		UpdateContext: resourceWireGuardInterfaceUpdate,
+		DeleteContext: resourceWireGuardInterfaceDelete,
+		CustomizeDiff: resourceWireGuardInterfaceCustomizeDiff,
+		Schema: map[string]*schema.Schema{
+			"interface": &schema.Schema{
+				Type:         schema.TypeInt,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntBetween(0, 2),
+			},
+			"enabled": &schema.Schema{
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+			"address": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ValidateFunc: validation.IsCIDR,
+			},
+			"port": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      51820,
+				ValidateFunc: validation.IsPortNumber,
+			},
+			"endpoint": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"mtu": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      1420,
+				ValidateFunc: validation.IntBetween(576, 1500),
+			},
+			"dns": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"private_key": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				Sensitive:    true,
+				ValidateFunc: validateWireGuardKey,
+			},
+			"public_key": &schema.Schema{
+				Type:     schema.TypeString,
+				Computed: true,
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func wireguardInterfaceFields(iface int) []nvramField {
+	prefix := fmt.Sprintf("wg%d_", iface)
+	return []nvramField{
+		{attr: "enabled", key: prefix + "enable", kind: nvramBool},
+		{attr: "address", key: prefix + "ip", kind: nvramString},
+		{attr: "port", key: prefix + "port", kind: nvramInt},
+		{attr: "endpoint", key: prefix + "endpoint", kind: nvramString},
+		{attr: "mtu", key: prefix + "mtu", kind: nvramInt},
+		{attr: "dns", key: prefix + "dns", kind: nvramString},
+		{attr: "private_key", key: prefix + "key", kind: nvramString},
+	}
+}
+
+// public_key follows private_key, show the new value in the plan when it can be derived
+func resourceWireGuardInterfaceCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if !d.HasChange("private_key") {
+		return nil
+	}
+	if private, ok := d.GetOk("private_key"); ok && d.NewValueKnown("private_key") {
+		if public, err := wireguardPublicKey(private.(string)); err == nil {
+			return d.SetNew("public_key", public)
+		}
+	}
+	return d.SetNewComputed("public_key")
+}
+
+func resourceWireGuardInterfaceCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	wireguardLock.Lock()
+	defer wireguardLock.Unlock()
+
+	iface := d.Get("interface").(int)
+
+	if d.Get("private_key").(string) == "" {
+		key, err := wireguardGenerateKey()
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		if err := d.Set("private_key", key); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	entries := make(map[string]string)
+	nvramFieldsWrite(d, wireguardInterfaceFields(iface), entries)
+
+	service := fmt.Sprintf("wireguard%d-stop", iface)
+	if d.Get("enabled").(bool) {
+		service = fmt.Sprintf("wireguard%d-restart", iface)
+	}
+
+	b, err := c.applyChange(service, encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(strconv.Itoa(iface))
+
+	resourceWireGuardInterfaceRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceWireGuardInterfaceRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	iface, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	if _, found := n[fmt.Sprintf("wg%d_key", iface)]; !found {
+		d.SetId("")
+		return diags
+	}
+
+	if err := d.Set("interface", iface); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := nvramFieldsRead(d, n, wireguardInterfaceFields(iface)); err != nil {
+		return diag.FromErr(err)
+	}
+
+	public, err := wireguardPublicKey(d.Get("private_key").(string))
+	if err != nil {
+		public = ""
+	}
+	if err := d.Set("public_key", public); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceWireGuardInterfaceUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	diags := resourceWireGuardInterfaceCreate(ctx, d, m)
+	if diags.HasError() {
+		return diags
+	}
+	return resourceWireGuardInterfaceRead(ctx, d, m)
+}
+
+func resourceWireGuardInterfaceDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	c := m.(*Client)
+
+	wireguardLock.Lock()
+	defer wireguardLock.Unlock()
+
+	iface, err := strconv.Atoi(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	// stop the interface and drop its key, peers are owned by tomato_wireguard_peer
+	prefix := fmt.Sprintf("wg%d_", iface)
+	entries := map[string]string{
+		prefix + "enable": "0",
+		prefix + "key":    "",
+	}
+
+	b, err := c.applyChange(fmt.Sprintf("wireguard%d-stop", iface), encodeNVRAM(entries))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func wireguardGenerateKey() (string, error) {
+	key := make([]byte, curve25519.ScalarSize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+
+	// clamp as wg genkey does
+	key[0] &= 248
+	key[31] = (key[31] & 127) | 64
+
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func wireguardPublicKey(private string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(private)
+	if err != nil {
+		return "", err
+	}
+
+	public, err := curve25519.X25519(key, curve25519.Basepoint)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(public), nil
+}
+
+func validateWireGuardKey(i interface{}, k string) ([]string, []error) {
+	key, err := base64.StdEncoding.DecodeString(i.(string))
+	if err != nil || len(key) != curve25519.ScalarSize {
+		return nil, []error{fmt.Errorf("%s is not a base64 encoded 32 byte WireGuard key", k)}
+	}
+	return nil, nil
+}
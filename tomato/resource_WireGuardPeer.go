@@ -0,0 +1,316 @@
+package tomato
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// fields of a wgN_peers record, records are terminated by >
+const (
+	wireguardPeerPriv = iota
+	wireguardPeerName
+	wireguardPeerEndpoint
+	wireguardPeerPrivateKey
+	wireguardPeerPublicKey
+	wireguardPeerPresharedKey
+	wireguardPeerAddress
+	wireguardPeerAllowedIPs
+	wireguardPeerKeepalive
+	wireguardPeerFieldCount
+)
+
+func resourceWireGuardPeer() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceWireGuardPeerCreate,
+		ReadContext:   resourceWireGuardPeerRead,
+		UpdateContext: resourceWireGuardPeerUpdate,
+		DeleteContext: resourceWireGuardPeerDelete,
+		Schema: map[string]*schema.Schema{
+			"interface": &schema.Schema{
+				Type:         schema.TypeInt,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validation.IntBetween(0, 2),
+			},
+			"public_key": &schema.Schema{
+				Type:         schema.TypeString,
+				Required:     true,
+				ForceNew:     true,
+				ValidateFunc: validateWireGuardKey,
+			},
+			"name": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"address": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				ValidateFunc: validation.IsCIDR,
+			},
+			"allowed_ips": &schema.Schema{
+				Type:     schema.TypeList,
+				Required: true,
+				Elem: &schema.Schema{
+					Type:         schema.TypeString,
+					ValidateFunc: validation.IsCIDR,
+				},
+			},
+			"endpoint": &schema.Schema{
+				Type:     schema.TypeString,
+				Optional: true,
+			},
+			"keepalive": &schema.Schema{
+				Type:         schema.TypeInt,
+				Optional:     true,
+				Default:      0,
+				ValidateFunc: validation.IntBetween(0, 65535),
+			},
+			"preshared_key": &schema.Schema{
+				Type:         schema.TypeString,
+				Optional:     true,
+				Sensitive:    true,
+				ValidateFunc: validateWireGuardKey,
+			},
+		},
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+	}
+}
+
+func resourceWireGuardPeerCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	wireguardLock.Lock()
+	defer wireguardLock.Unlock()
+
+	iface := d.Get("interface").(int)
+	public_key := d.Get("public_key").(string)
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	key := fmt.Sprintf("wg%d_peers", iface)
+	peers := n[key]
+
+	if eentry, _ := wireguardPeerFindEntry(public_key, peers); eentry != "" {
+		return diag.FromErr(fmt.Errorf("peer %s already exists on wg%d", public_key, iface))
+	}
+
+	entry := wireguardPeerRender(d, nil)
+
+	tflog.Debug(ctx, "Entry peer:\n"+entry)
+
+	b, err := c.applyChange(fmt.Sprintf("wireguard%d-restart", iface), encodeNVRAM(map[string]string{key: peers + entry}))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(fmt.Sprintf("%d:%s", iface, public_key))
+
+	resourceWireGuardPeerRead(ctx, d, m)
+
+	return diags
+}
+
+func resourceWireGuardPeerRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*Client)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	iface, public_key, err := wireguardPeerParseID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	entry, fields := wireguardPeerFindEntry(public_key, n[fmt.Sprintf("wg%d_peers", iface)])
+	if entry == "" {
+		d.SetId("")
+		return diags
+	}
+
+	allowed_ips := []string{}
+	for _, aip := range strings.Split(fields[wireguardPeerAllowedIPs], ",") {
+		if aip = strings.TrimSpace(aip); aip != "" {
+			allowed_ips = append(allowed_ips, aip)
+		}
+	}
+	keepalive, _ := strconv.Atoi(fields[wireguardPeerKeepalive])
+
+	if err := d.Set("interface", iface); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("public_key", fields[wireguardPeerPublicKey]); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("name", fields[wireguardPeerName]); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("address", fields[wireguardPeerAddress]); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("allowed_ips", allowed_ips); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("endpoint", fields[wireguardPeerEndpoint]); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("keepalive", keepalive); err != nil {
+		return diag.FromErr(err)
+	}
+	if err := d.Set("preshared_key", fields[wireguardPeerPresharedKey]); err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+func resourceWireGuardPeerUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+
+	c := m.(*Client)
+
+	wireguardLock.Lock()
+	defer wireguardLock.Unlock()
+
+	iface, public_key, err := wireguardPeerParseID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	key := fmt.Sprintf("wg%d_peers", iface)
+	peers := n[key]
+
+	eentry, efields := wireguardPeerFindEntry(public_key, peers)
+	if eentry == "" {
+		return diag.FromErr(errors.New("ID Not Found"))
+	}
+
+	tflog.Debug(ctx, "Existing peer:\n"+eentry)
+
+	peers = strings.Replace(peers, eentry, wireguardPeerRender(d, efields), -1)
+
+	b, err := c.applyChange(fmt.Sprintf("wireguard%d-restart", iface), encodeNVRAM(map[string]string{key: peers}))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return resourceWireGuardPeerRead(ctx, d, m)
+}
+
+func resourceWireGuardPeerDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	c := m.(*Client)
+
+	wireguardLock.Lock()
+	defer wireguardLock.Unlock()
+
+	iface, public_key, err := wireguardPeerParseID(d.Id())
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	n, err := c.getNVRAM()
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	key := fmt.Sprintf("wg%d_peers", iface)
+	peers := n[key]
+
+	entry, _ := wireguardPeerFindEntry(public_key, peers)
+	if len(entry) == 0 {
+		return diags
+	}
+
+	peers = strings.Replace(peers, entry, "", -1)
+
+	b, err := c.applyChange(fmt.Sprintf("wireguard%d-restart", iface), encodeNVRAM(map[string]string{key: peers}))
+
+	tflog.Debug(ctx, b)
+
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	return diags
+}
+
+// the ID is <interface>:<public key>
+func wireguardPeerParseID(id string) (int, string, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) != 2 {
+		return 0, "", fmt.Errorf("invalid ID %q, expected <interface>:<public key>", id)
+	}
+	iface, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid ID %q, expected <interface>:<public key>", id)
+	}
+	return iface, parts[1], nil
+}
+
+// fields the resource does not manage, like the peer private key set in the web UI, are kept from the existing record
+func wireguardPeerRender(d *schema.ResourceData, existing []string) string {
+	allowed_ips := []string{}
+	for _, aip := range d.Get("allowed_ips").([]interface{}) {
+		allowed_ips = append(allowed_ips, aip.(string))
+	}
+
+	fields := make([]string, wireguardPeerFieldCount)
+	fields[wireguardPeerPriv] = "0"
+	if len(existing) >= wireguardPeerFieldCount {
+		fields = append([]string{}, existing...)
+	}
+	fields[wireguardPeerName] = d.Get("name").(string)
+	fields[wireguardPeerEndpoint] = d.Get("endpoint").(string)
+	fields[wireguardPeerPublicKey] = d.Get("public_key").(string)
+	fields[wireguardPeerPresharedKey] = d.Get("preshared_key").(string)
+	fields[wireguardPeerAddress] = d.Get("address").(string)
+	fields[wireguardPeerAllowedIPs] = strings.Join(allowed_ips, ",")
+	fields[wireguardPeerKeepalive] = strconv.Itoa(d.Get("keepalive").(int))
+
+	return strings.Join(fields, "<") + ">"
+}
+
+func wireguardPeerFindEntry(public_key, peers string) (string, []string) {
+	for _, record := range strings.Split(peers, ">") {
+		fields := strings.Split(record, "<")
+		if len(fields) < wireguardPeerFieldCount {
+			continue
+		}
+		if fields[wireguardPeerPublicKey] == public_key {
+			return record + ">", fields
+		}
+	}
+	return "", nil
+}
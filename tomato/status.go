@@ -0,0 +1,328 @@
+package tomato
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// run one of the update.cgi commands the status pages poll and return the javascript it prints,
+// args are sent as arg0, arg1, ...
+func (c *Client) execCGI(exec string, args ...string) (string, error) {
+	if c.Auth.Username == "" || c.Auth.Password == "" {
+		return "", fmt.Errorf("define username and password")
+	}
+	if c.HttpID == "" {
+		return "", fmt.Errorf("Missing http_id, authentication failed")
+	}
+
+	rb := fmt.Sprintf("exec=%s&_http_id=%s", exec, c.HttpID)
+	for i, arg := range args {
+		rb += fmt.Sprintf("&arg%d=%s", i, url.QueryEscape(arg))
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/update.cgi", c.HostURL), bytes.NewBuffer([]byte(rb)))
+	if err != nil {
+		return "", err
+	}
+
+	b, err := c.doRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// fetch one of the pages or scripts the web UI loads
+func (c *Client) getPage(page string) (string, error) {
+	if c.Auth.Username == "" || c.Auth.Password == "" {
+		return "", fmt.Errorf("define username and password")
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/%s", c.HostURL, page), nil)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := c.doRequest(req)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// parse the literal assigned to name in the javascript returned by the status pages.
+// Arrays come back as []interface{}, objects as map[string]interface{}, numbers as float64.
+func jsVar(js, name string) (interface{}, error) {
+	re := regexp.MustCompile(`(?m)(?:^|[\s;,{])` + regexp.QuoteMeta(name) + `\s*=\s*`)
+	loc := re.FindStringIndex(js)
+	if loc == nil {
+		return nil, fmt.Errorf("%s not found", name)
+	}
+
+	p := &jsParser{s: js, i: loc[1]}
+	v, err := p.value()
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s: %s", name, err)
+	}
+	return v, nil
+}
+
+// like jsVar but returns the rows of a two dimensional array
+func jsRows(js, name string) ([][]interface{}, error) {
+	v, err := jsVar(js, name)
+	if err != nil {
+		return nil, err
+	}
+
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s is not an array", name)
+	}
+
+	rows := [][]interface{}{}
+	for _, r := range list {
+		if row, ok := r.([]interface{}); ok {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// the string form of a parsed javascript value
+func jsString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// the integer form of a parsed javascript value, numbers are often sent as strings
+func jsInt(v interface{}) int {
+	switch t := v.(type) {
+	case float64:
+		return int(t)
+	case string:
+		s := strings.TrimSpace(t)
+		if strings.HasPrefix(s, "0x") {
+			i, _ := strconv.ParseInt(s[2:], 16, 64)
+			return int(i)
+		}
+		f, _ := strconv.ParseFloat(s, 64)
+		return int(f)
+	case bool:
+		if t {
+			return 1
+		}
+	}
+	return 0
+}
+
+// row[i] or nil when the row is too short
+func jsField(row []interface{}, i int) interface{} {
+	if i < len(row) {
+		return row[i]
+	}
+	return nil
+}
+
+type jsParser struct {
+	s string
+	i int
+}
+
+func (p *jsParser) skip() {
+	for p.i < len(p.s) && strings.ContainsRune(" \t\r\n", rune(p.s[p.i])) {
+		p.i++
+	}
+}
+
+func (p *jsParser) value() (interface{}, error) {
+	p.skip()
+	if p.i >= len(p.s) {
+		return nil, fmt.Errorf("unexpected end of input")
+	}
+
+	switch c := p.s[p.i]; {
+	case c == '[':
+		return p.array()
+	case c == '{':
+		return p.object()
+	case c == '\'' || c == '"':
+		return p.str()
+	case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return p.number()
+	case strings.HasPrefix(p.s[p.i:], "new Array("):
+		p.i += len("new Array")
+		return p.list('(', ')')
+	}
+
+	word := p.ident()
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null", "undefined":
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", word, p.i)
+}
+
+func (p *jsParser) array() (interface{}, error) {
+	return p.list('[', ']')
+}
+
+func (p *jsParser) list(open, close byte) (interface{}, error) {
+	list := []interface{}{}
+	p.i++ // open
+	for {
+		p.skip()
+		if p.i >= len(p.s) {
+			return nil, fmt.Errorf("unterminated %c", open)
+		}
+		if p.s[p.i] == close {
+			p.i++
+			return list, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+		p.skip()
+		if p.i < len(p.s) && p.s[p.i] == ',' {
+			p.i++
+		}
+	}
+}
+
+func (p *jsParser) object() (interface{}, error) {
+	obj := make(map[string]interface{})
+	p.i++ // {
+	for {
+		p.skip()
+		if p.i >= len(p.s) {
+			return nil, fmt.Errorf("unterminated {")
+		}
+		if p.s[p.i] == '}' {
+			p.i++
+			return obj, nil
+		}
+
+		var key string
+		if c := p.s[p.i]; c == '\'' || c == '"' {
+			k, err := p.str()
+			if err != nil {
+				return nil, err
+			}
+			key = k.(string)
+		} else {
+			key = p.ident()
+		}
+
+		p.skip()
+		if p.i >= len(p.s) || p.s[p.i] != ':' {
+			return nil, fmt.Errorf("expected : after %s", key)
+		}
+		p.i++
+
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		obj[key] = v
+
+		p.skip()
+		if p.i < len(p.s) && p.s[p.i] == ',' {
+			p.i++
+		}
+	}
+}
+
+func (p *jsParser) str() (interface{}, error) {
+	quote := p.s[p.i]
+	p.i++
+
+	var sb strings.Builder
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		p.i++
+		switch {
+		case c == quote:
+			return sb.String(), nil
+		case c == '\\' && p.i < len(p.s):
+			e := p.s[p.i]
+			p.i++
+			switch e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'x':
+				if p.i+2 <= len(p.s) {
+					if b, err := strconv.ParseUint(p.s[p.i:p.i+2], 16, 8); err == nil {
+						sb.WriteByte(byte(b))
+						p.i += 2
+						continue
+					}
+				}
+				sb.WriteByte(e)
+			case 'u':
+				if p.i+4 <= len(p.s) {
+					if r, err := strconv.ParseUint(p.s[p.i:p.i+4], 16, 32); err == nil {
+						sb.WriteRune(rune(r))
+						p.i += 4
+						continue
+					}
+				}
+				sb.WriteByte(e)
+			default:
+				sb.WriteByte(e)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return nil, fmt.Errorf("unterminated string")
+}
+
+func (p *jsParser) number() (interface{}, error) {
+	start := p.i
+	for p.i < len(p.s) && strings.ContainsRune("+-.0123456789abcdefxABCDEFX", rune(p.s[p.i])) {
+		p.i++
+	}
+	text := p.s[start:p.i]
+	if strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X") {
+		i, err := strconv.ParseInt(text[2:], 16, 64)
+		return float64(i), err
+	}
+	return strconv.ParseFloat(text, 64)
+}
+
+func (p *jsParser) ident() string {
+	start := p.i
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		if !(c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			break
+		}
+		p.i++
+	}
+	return p.s[start:p.i]
+}
@@ -0,0 +1,204 @@
+package tomato
+
+import (
+	"reflect"
+	"testing"
+)
+
+// trimmed from update.cgi?exec=devlist on a FreshTomato 2023.5 RT-AC68U
+const testDevlist = `arplist = [['10.6.4.20','00:11:22:33:44:55','br0'],['10.6.5.7','AA:BB:CC:DD:EE:FF','br1']];
+wlnoise = [-91,-89];
+wldev = [['eth1','00:11:22:33:44:66',-45,144000,130000,3600,0],['eth2','00:11:22:33:44:77',-67,866000,780000,61,1]];
+dhcpd_lease = [ ['laptop','10.6.4.20','00:11:22:33:44:55','0 days, 11:59:04'],['','10.6.5.7','AA:BB:CC:DD:EE:FF','1 day, 00:00:10']];
+dhcpd_static = 'x';
+`
+
+func TestJsVar(t *testing.T) {
+	cases := []struct {
+		js   string
+		name string
+		want interface{}
+	}{
+		{`a = 'it\'s';`, "a", "it's"},
+		{`a = "tab\there \x41é";`, "a", "tab\there Aé"},
+		{`x = 1; a = -1.5;`, "a", -1.5},
+		{`a = 0x1f;`, "a", float64(31)},
+		{`a = [1, 'b', true, null, ];`, "a", []interface{}{float64(1), "b", true, nil}},
+		{`a = new Array('x', 2);`, "a", []interface{}{"x", float64(2)}},
+		{`netdev = { 'vlan2': { rx: 0x10, tx: 0x20 } };`, "netdev", map[string]interface{}{"vlan2": map[string]interface{}{"rx": float64(16), "tx": float64(32)}}},
+		// names are matched whole, not as a suffix
+		{`wlnoise = [1]; noise = [2];`, "noise", []interface{}{float64(2)}},
+	}
+	for _, c := range cases {
+		got, err := jsVar(c.js, c.name)
+		if err != nil {
+			t.Errorf("jsVar(%q, %q): %s", c.js, c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("jsVar(%q, %q) = %#v, want %#v", c.js, c.name, got, c.want)
+		}
+	}
+
+	for _, js := range []string{`a = [1, 2`, `a = 'open`, `a = {b 1}`, `b = 1`} {
+		if _, err := jsVar(js, "a"); err == nil {
+			t.Errorf("jsVar(%q) should fail", js)
+		}
+	}
+}
+
+func TestJsInt(t *testing.T) {
+	cases := map[interface{}]int{
+		float64(12): 12,
+		"42":        42,
+		" 0x1A ":    26,
+		"1.9":       1,
+		true:        1,
+		nil:         0,
+		"junk":      0,
+	}
+	for v, want := range cases {
+		if got := jsInt(v); got != want {
+			t.Errorf("jsInt(%#v) = %d, want %d", v, got, want)
+		}
+	}
+}
+
+func TestDHCPLeasesDevlist(t *testing.T) {
+	leases, err := jsRows(testDevlist, "dhcpd_lease")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(leases) != 2 || jsString(jsField(leases[0], 0)) != "laptop" {
+		t.Fatalf("unexpected dhcpd_lease %v", leases)
+	}
+	if got := leaseSeconds(jsString(jsField(leases[0], 3))); got != 11*3600+59*60+4 {
+		t.Errorf("leaseSeconds = %d", got)
+	}
+	if got := leaseSeconds(jsString(jsField(leases[1], 3))); got != 86400+10 {
+		t.Errorf("leaseSeconds = %d", got)
+	}
+	if jsField(leases[0], 20) != nil {
+		t.Errorf("jsField past the end should be nil")
+	}
+
+	if _, err := jsRows(testDevlist, "dhcpd_static"); err == nil {
+		t.Errorf("jsRows on a string should fail")
+	}
+}
+
+func TestDevicesDevlist(t *testing.T) {
+	arp, err := jsRows(testDevlist, "arplist")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(arp) != 2 || jsString(jsField(arp[1], 0)) != "10.6.5.7" || jsString(jsField(arp[1], 2)) != "br1" {
+		t.Errorf("unexpected arplist %v", arp)
+	}
+
+	wldev, err := jsRows(testDevlist, "wldev")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := jsInt(jsField(wldev[0], 2)); got != -45 {
+		t.Errorf("rssi = %d, want -45", got)
+	}
+	if got := jsInt(jsField(wldev[1], 5)); got != 61 {
+		t.Errorf("connected = %d, want 61", got)
+	}
+}
+
+func TestWirelessSurveySpan(t *testing.T) {
+	cases := []struct {
+		band            string
+		channel, width  int
+		wantLow, wantHi int
+	}{
+		{"2.4", 6, 20, 2, 10},
+		{"2.4", 1, 40, -7, 9},
+		{"5", 44, 20, 44, 44},
+		{"5", 44, 80, 36, 48},
+		{"5", 157, 80, 149, 161},
+		{"5", 40, 40, 36, 40},
+	}
+	for _, c := range cases {
+		low, high := wirelessSurveySpan(c.band, c.channel, c.width)
+		if low != c.wantLow || high != c.wantHi {
+			t.Errorf("wirelessSurveySpan(%s, %d, %d) = %d-%d, want %d-%d", c.band, c.channel, c.width, low, high, c.wantLow, c.wantHi)
+		}
+	}
+}
+
+func TestWirelessSurveyRecommend(t *testing.T) {
+	networks := []interface{}{
+		map[string]interface{}{"band": "2.4", "channel": 1, "width": 20, "rssi": -40},
+		map[string]interface{}{"band": "2.4", "channel": 11, "width": 20, "rssi": -80},
+		map[string]interface{}{"band": "2.4", "channel": 6, "width": 20, "rssi": -50},
+		map[string]interface{}{"band": "5", "channel": 36, "width": 80, "rssi": -60},
+	}
+	if got := wirelessSurveyRecommend("2.4", wirelessSurveyChannels["2.4"], networks); got != 11 {
+		t.Errorf("2.4GHz recommendation = %d, want 11", got)
+	}
+	if got := wirelessSurveyRecommend("5", wirelessSurveyChannels["5"], networks); got != 149 {
+		t.Errorf("5GHz recommendation = %d, want 149", got)
+	}
+}
+
+func TestBandwidthDate(t *testing.T) {
+	// 2026-10-19, months are zero based
+	packed := (2026-1900)<<16 | 9<<8 | 19
+	if got := bandwidthDate(packed, "daily"); got != "2026-10-19" {
+		t.Errorf("daily = %s", got)
+	}
+	if got := bandwidthDate(packed, "monthly"); got != "2026-10" {
+		t.Errorf("monthly = %s", got)
+	}
+}
+
+// trimmed from update.cgi?exec=ctdump
+const testCtdump = `ctdump = [
+[6,431990,'10.6.4.20','93.184.216.34',51234,443,0x1f40,0x20a3c,2,0],
+[17,120,'10.6.4.21','9.9.9.9',40000,53,120,240,0,-1]];
+`
+
+func TestConntrackCtdump(t *testing.T) {
+	rows, err := jsRows(testCtdump, "ctdump")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	if got := conntrackProto(jsField(rows[0], 0)); got != "tcp" {
+		t.Errorf("proto = %s, want tcp", got)
+	}
+	if got := conntrackProto(jsField(rows[1], 0)); got != "udp" {
+		t.Errorf("proto = %s, want udp", got)
+	}
+	if got := jsInt(jsField(rows[0], 6)); got != 0x1f40 {
+		t.Errorf("bytes out = %d", got)
+	}
+	if got := jsInt(jsField(rows[1], 9)); got != -1 {
+		t.Errorf("rule = %d, want -1", got)
+	}
+}
+
+func TestLogLine(t *testing.T) {
+	match := logLineRe.FindStringSubmatch("Oct  9 02:52:49 router daemon.info dnsmasq[1234]: read /etc/hosts - 5 addresses")
+	want := []string{"Oct  9 02:52:49", "router", "daemon", "info", "dnsmasq", "1234", "read /etc/hosts - 5 addresses"}
+	if match == nil || !reflect.DeepEqual(match[1:], want) {
+		t.Errorf("got %q, want %q", match, want)
+	}
+
+	match = logLineRe.FindStringSubmatch("Oct 19 12:00:00 router kern.warn kernel: eth1: link up")
+	if match == nil || match[5] != "kernel" || match[6] != "" || match[7] != "eth1: link up" {
+		t.Errorf("got %q", match)
+	}
+}
+
+func TestStatsTime(t *testing.T) {
+	v, err := jsVar("stats = {};\nstats.time = 'Mon, 19 Oct 2026 12:52:49 +0200';", "stats.time")
+	if err != nil || jsString(v) != "Mon, 19 Oct 2026 12:52:49 +0200" {
+		t.Errorf("stats.time = %#v, %v", v, err)
+	}
+}